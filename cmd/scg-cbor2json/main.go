@@ -0,0 +1,80 @@
+// Command scg-cbor2json converts records written by handlers.CBOR into JSON lines.
+//
+// Usage:
+//
+//	scg-cbor2json [file ...]
+//
+// With no arguments it reads standard input. Output goes to standard output.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/next-trace/scg-logger/logger/handlers"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "scg-cbor2json:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	out := bufio.NewWriter(stdout)
+
+	if len(args) == 0 {
+		if err := convert(stdin, out); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range args {
+		if err := convertFile(name, out); err != nil {
+			return err
+		}
+	}
+
+	return out.Flush()
+}
+
+func convertFile(name string, out *bufio.Writer) error {
+	f, err := os.Open(name) //nolint:gosec // reading user-supplied paths is the purpose of this tool.
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := convert(f, out); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}
+
+func convert(r io.Reader, out *bufio.Writer) error {
+	dec := handlers.NewCBORDecoder(r)
+
+	var line []byte
+
+	for {
+		var err error
+
+		line, err = dec.DecodeJSON(line[:0])
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := out.Write(line); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/next-trace/scg-logger/logger/handlers"
+)
+
+// writeRecords encodes one CBOR record per message.
+func writeRecords(msgs ...string) []byte {
+	var buf bytes.Buffer
+
+	l := slog.New(handlers.CBOR(&buf, slog.HandlerOptions{}))
+	for i, msg := range msgs {
+		l.Info(msg, "n", i)
+	}
+
+	return buf.Bytes()
+}
+
+// decodeLines parses each JSON line of out.
+func decodeLines(t *testing.T, out string) []map[string]any {
+	t.Helper()
+
+	var recs []map[string]any
+
+	for line := range strings.Lines(out) {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("line is not JSON: %v %q", err, line)
+		}
+
+		recs = append(recs, m)
+	}
+
+	return recs
+}
+
+// TestRunConvertsStdin ensures records on stdin become JSON lines in order.
+func TestRunConvertsStdin(t *testing.T) {
+	var out bytes.Buffer
+
+	if err := run(nil, bytes.NewReader(writeRecords("first", "second")), &out); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	recs := decodeLines(t, out.String())
+	if len(recs) != 2 || recs[0]["msg"] != "first" || recs[1]["msg"] != "second" || recs[1]["n"] != float64(1) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+// TestRunConvertsFiles ensures every file argument is converted in order.
+func TestRunConvertsFiles(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.cbor")
+	b := filepath.Join(dir, "b.cbor")
+
+	if err := os.WriteFile(a, writeRecords("from a"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(b, writeRecords("from b"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+
+	if err := run([]string{a, b}, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	recs := decodeLines(t, out.String())
+	if len(recs) != 2 || recs[0]["msg"] != "from a" || recs[1]["msg"] != "from b" {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+// TestRunReportsCorruptInput ensures a malformed file is reported with its name.
+func TestRunReportsCorruptInput(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "bad.cbor")
+
+	rec := writeRecords("line")
+	if err := os.WriteFile(name, rec[:len(rec)-2], 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := run([]string{name}, strings.NewReader(""), &bytes.Buffer{})
+	if !errors.Is(err, handlers.ErrCBORMalformed) || !strings.Contains(err.Error(), "bad.cbor") {
+		t.Fatalf("expected ErrCBORMalformed naming the file, got %v", err)
+	}
+
+	if err := run([]string{filepath.Join(t.TempDir(), "missing")}, nil, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
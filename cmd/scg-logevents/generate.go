@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+)
+
+var goTemplate = template.Must(template.New("go").Funcs(template.FuncMap{
+	"param":   paramName,
+	"goType":  func(t string) string { return fieldTypes[t].goType },
+	"ctor":    func(t string) string { return fieldTypes[t].ctor },
+	"method":  func(level string) string { return strings.ToUpper(level[:1]) + level[1:] + "Ctx" },
+	"comment": commentLines,
+	"usesTime": func(s *schema) bool {
+		for _, e := range s.Events {
+			for _, f := range e.Fields {
+				if f.Type == "duration" || f.Type == "time" {
+					return true
+				}
+			}
+		}
+
+		return false
+	},
+}).Parse(`// Code generated by scg-logevents from {{.Source}}. DO NOT EDIT.
+
+package {{.Schema.Package}}
+
+import (
+	"context"
+{{- if usesTime .Schema}}
+	"time"
+{{- end}}
+
+	"github.com/next-trace/scg-logger/contract"
+	"github.com/next-trace/scg-logger/fields"
+)
+
+// KeyEventID is the field carrying the stable ID of every event below.
+const KeyEventID = "{{.KeyEventID}}"
+
+// Event IDs.
+const (
+{{- range .Schema.Events}}
+	Event{{.Name}} = {{printf "%q" .ID}}
+{{- end}}
+)
+{{range .Schema.Events}}
+// Log{{.Name}} logs the {{.ID}} event at {{.Level}} level.
+{{- if .Description}}
+//
+{{comment .Description}}
+{{- end}}
+func Log{{.Name}}(ctx context.Context, l contract.Logger
+{{- if eq .Level "error"}}, err error{{end}}
+{{- range .Fields}}, {{param .Name}} {{goType .Type}}{{end}}) {
+	l.{{method .Level}}(ctx, {{printf "%q" .Message}},
+{{- if eq .Level "error"}} err,{{end}}
+		fields.String(KeyEventID, Event{{.Name}}),
+{{- range .Fields}}
+		{{ctor .Type}}({{printf "%q" .Name}}, {{param .Name}}),
+{{- end}}
+	)
+}
+{{end}}`))
+
+// commentLines renders text as // comment lines.
+func commentLines(text string) string {
+	var b strings.Builder
+
+	for i, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+
+		b.WriteString(strings.TrimRight("// "+line, " "))
+	}
+
+	return b.String()
+}
+
+// generateGo renders the typed logging functions, gofmt-ed.
+func generateGo(s *schema, source string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := goTemplate.Execute(&buf, map[string]any{"Schema": s, "Source": source, "KeyEventID": keyEventID})
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+
+	return out, nil
+}
+
+// generateMarkdown renders the event catalog.
+func generateMarkdown(s *schema, source string) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "<!-- Code generated by scg-logevents from %s. DO NOT EDIT. -->\n\n", source)
+	b.WriteString("# Log events\n\n")
+	fmt.Fprintf(&b, "Every event carries its ID in the `%s` field.\n\n", keyEventID)
+	b.WriteString("| Event | ID | Level | Message |\n|---|---|---|---|\n")
+
+	for _, e := range s.Events {
+		fmt.Fprintf(&b, "| [%s](#%s) | `%s` | %s | %s |\n", e.Name, strings.ToLower(e.Name), e.ID, e.Level, mdCell(e.Message))
+	}
+
+	for _, e := range s.Events {
+		fmt.Fprintf(&b, "\n## %s\n\n", e.Name)
+		fmt.Fprintf(&b, "- ID: `%s`\n- Level: %s\n- Message: %s\n- Function: `Log%s`\n", e.ID, e.Level, mdCell(e.Message), e.Name)
+
+		if e.Description != "" {
+			fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(e.Description))
+		}
+
+		if len(e.Fields) == 0 {
+			continue
+		}
+
+		b.WriteString("\n| Field | Type | Description |\n|---|---|---|\n")
+
+		for _, f := range e.Fields {
+			fmt.Fprintf(&b, "| `%s` | %s | %s |\n", f.Name, f.Type, mdCell(f.Description))
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// mdCell makes text safe for a Markdown table cell.
+func mdCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(strings.TrimSpace(s), "|", `\|`), "\n", " ")
+}
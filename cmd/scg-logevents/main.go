@@ -0,0 +1,118 @@
+// Command scg-logevents generates typed event loggers from a YAML schema.
+//
+// Usage:
+//
+//	scg-logevents -in events.yaml [-out events_gen.go] [-pkg name] [-doc EVENTS.md]
+//
+// Typically run with go:generate next to the schema:
+//
+//	//go:generate go run github.com/next-trace/scg-logger/cmd/scg-logevents -in events.yaml -doc EVENTS.md
+//
+// The schema lists events with a name, an optional stable id (derived from the name when
+// omitted), a level, a message, a description and typed fields:
+//
+//	package: events
+//	events:
+//	  - name: OrderPlaced
+//	    level: info
+//	    message: order placed
+//	    description: A customer placed an order.
+//	    fields:
+//	      - name: order_id
+//	        type: string
+//	      - name: amount
+//	        type: int64
+//
+// Field types are string, int, int64, bool, float64, duration, time and any. For each event
+// it writes a function such as
+//
+//	func LogOrderPlaced(ctx context.Context, l contract.Logger, orderID string, amount int64)
+//
+// which logs the message with an event_id field and the typed fields. Error-level events
+// also take an err argument. Fields whose parameter would be a Go keyword or clash with
+// ctx, l, err or an imported package (context, contract, fields, time) get a Value suffix,
+// e.g. type -> typeValue. With -doc, a Markdown catalog of the events is written too.
+//
+// The package name comes from -pkg, then the schema's package key, then $GOPACKAGE.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "scg-logevents:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stderr io.Writer) error {
+	fs := flag.NewFlagSet("scg-logevents", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	in := fs.String("in", "", "YAML schema file (required)")
+	out := fs.String("out", "events_gen.go", "generated Go file")
+	pkg := fs.String("pkg", "", "package name of the generated file")
+	doc := fs.String("doc", "", "Markdown catalog to write, none when empty")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *in == "" {
+		fs.Usage()
+
+		return errors.New("-in is required")
+	}
+
+	src, err := os.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := parseYAML(string(src))
+	if err != nil {
+		return fmt.Errorf("%s: %w", *in, err)
+	}
+
+	s, err := decodeSchema(parsed)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *in, err)
+	}
+
+	switch {
+	case *pkg != "":
+		s.Package = *pkg
+	case s.Package == "":
+		s.Package = os.Getenv("GOPACKAGE")
+	}
+
+	if s.Package == "" {
+		return errors.New("no package name: set -pkg, the schema's package key or run with go:generate")
+	}
+
+	source := filepath.Base(*in)
+
+	code, err := generateGo(s, source)
+	if err != nil {
+		return err
+	}
+
+	//nolint:gosec // generated sources are meant to be readable.
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		return err
+	}
+
+	if *doc == "" {
+		return nil
+	}
+
+	//nolint:gosec // generated docs are meant to be readable.
+	return os.WriteFile(*doc, generateMarkdown(s, source), 0o644)
+}
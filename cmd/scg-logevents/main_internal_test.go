@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite example/events from its schema")
+
+// exampleDir holds the schema and generated files checked by TestExampleIsUpToDate.
+const exampleDir = "../../example/events"
+
+// TestExampleIsUpToDate regenerates example/events and compares it with the checked-in
+// files, then compiles the regenerated code. Run with -update after changing the generator.
+func TestExampleIsUpToDate(t *testing.T) {
+	out := t.TempDir()
+	if *update {
+		out = exampleDir
+	}
+
+	goFile, docFile := filepath.Join(out, "events_gen.go"), filepath.Join(out, "EVENTS.md")
+
+	var stderr bytes.Buffer
+
+	err := run([]string{"-in", filepath.Join(exampleDir, "events.yaml"), "-out", goFile, "-doc", docFile}, &stderr)
+	if err != nil {
+		t.Fatalf("run: %v\n%s", err, stderr.String())
+	}
+
+	for _, name := range []string{"events_gen.go", "EVENTS.md"} {
+		want, err := os.ReadFile(filepath.Join(exampleDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is stale, run go test ./cmd/scg-logevents -update:\n%s", name, got)
+		}
+	}
+
+	compile(t, goFile)
+}
+
+// compile builds example/events with its generated file replaced by goFile.
+func compile(t *testing.T, goFile string) {
+	t.Helper()
+
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+
+	target, err := filepath.Abs(filepath.Join(exampleDir, "events_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	overlay, err := json.Marshal(map[string]any{"Replace": map[string]string{target: goFile}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	overlayFile := filepath.Join(t.TempDir(), "overlay.json")
+	if err := os.WriteFile(overlayFile, overlay, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	//nolint:gosec // runs the go command found on PATH with fixed arguments.
+	cmd := exec.CommandContext(t.Context(), gobin, "build", "-overlay", overlayFile, exampleDir)
+	if msg, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated code does not compile: %v\n%s", err, msg)
+	}
+}
+
+// TestRunReservedFieldNames ensures fields named like parameters or imports still compile.
+func TestRunReservedFieldNames(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "events.yaml")
+
+	src := `package: events
+events:
+  - name: Reserved
+    level: error
+    fields:
+      - name: fields
+        type: string
+      - name: contract
+        type: string
+      - name: context
+        type: string
+      - name: time
+        type: time
+      - name: err
+        type: string
+`
+	if err := os.WriteFile(in, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	goFile := filepath.Join(dir, "events_gen.go")
+	if err := run([]string{"-in", in, "-out", goFile}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	code, err := os.ReadFile(goFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := "err error, fieldsValue string, contractValue string, contextValue string, timeValue time.Time, errValue string)"
+	if !strings.Contains(string(code), sig) {
+		t.Fatalf("expected parameters %q in:\n%s", sig, code)
+	}
+
+	compile(t, goFile)
+}
+
+// TestRunRequiresInput ensures a missing -in flag is reported.
+func TestRunRequiresInput(t *testing.T) {
+	if err := run(nil, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "-in is required") {
+		t.Fatalf("expected -in error, got %v", err)
+	}
+}
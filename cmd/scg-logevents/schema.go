@@ -0,0 +1,225 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// schema is the decoded event schema file.
+type schema struct {
+	Package string
+	Events  []event
+}
+
+type event struct {
+	Name        string // Go name, e.g. OrderPlaced
+	ID          string // stable event_id, e.g. order_placed
+	Level       string // debug, info, warn or error
+	Message     string
+	Description string
+	Fields      []field
+}
+
+type field struct {
+	Name        string // log key, e.g. order_id
+	Type        string // one of fieldTypes
+	Description string
+}
+
+// fieldType maps a schema type to its Go type and the fields constructor used to log it.
+type fieldType struct {
+	goType string
+	ctor   string
+}
+
+var fieldTypes = map[string]fieldType{
+	"string":   {"string", "fields.String"},
+	"int":      {"int", "fields.Int"},
+	"int64":    {"int64", "fields.Int64"},
+	"bool":     {"bool", "fields.Bool"},
+	"float64":  {"float64", "fields.Any"},
+	"duration": {"time.Duration", "fields.Duration"},
+	"time":     {"time.Time", "fields.Time"},
+	"any":      {"any", "fields.Any"},
+}
+
+// keyEventID is the field carrying the event ID in every generated call.
+const keyEventID = "event_id"
+
+var levels = []string{"debug", "info", "warn", "error"}
+
+// decodeSchema converts a parsed YAML document into a schema and validates it.
+//
+//nolint:cyclop // one check per schema rule.
+func decodeSchema(doc any) (*schema, error) {
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return nil, errors.New("schema: top level must be a mapping")
+	}
+
+	s := &schema{}
+	s.Package, _ = root["package"].(string)
+
+	items, ok := root["events"].([]any)
+	if !ok || len(items) == 0 {
+		return nil, errors.New("schema: \"events\" must be a non-empty list")
+	}
+
+	names, ids := map[string]bool{}, map[string]bool{}
+
+	for i, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("schema: events[%d] must be a mapping", i)
+		}
+
+		e, err := decodeEvent(m)
+		if err != nil {
+			return nil, fmt.Errorf("schema: events[%d]: %w", i, err)
+		}
+
+		if names[e.Name] || ids[e.ID] {
+			return nil, fmt.Errorf("schema: events[%d]: duplicate event %s (%s)", i, e.Name, e.ID)
+		}
+
+		names[e.Name], ids[e.ID] = true, true
+		s.Events = append(s.Events, e)
+	}
+
+	return s, nil
+}
+
+func decodeEvent(m map[string]any) (event, error) {
+	e := event{Level: "info"}
+
+	for _, k := range []struct {
+		key string
+		dst *string
+	}{
+		{"name", &e.Name}, {"id", &e.ID}, {"level", &e.Level},
+		{"message", &e.Message}, {"description", &e.Description},
+	} {
+		if v, ok := m[k.key]; ok {
+			s, ok := v.(string)
+			if !ok {
+				return e, fmt.Errorf("%s must be a string", k.key)
+			}
+
+			*k.dst = s
+		}
+	}
+
+	if !token.IsIdentifier(e.Name) || !token.IsExported(e.Name) {
+		return e, fmt.Errorf("name %q must be an exported Go identifier", e.Name)
+	}
+
+	if e.ID == "" {
+		e.ID = snakeCase(e.Name)
+	}
+
+	if !slices.Contains(levels, e.Level) {
+		return e, fmt.Errorf("%s: level %q must be one of %s", e.Name, e.Level, strings.Join(levels, ", "))
+	}
+
+	if e.Message == "" {
+		e.Message = strings.ReplaceAll(e.ID, "_", " ")
+	}
+
+	list, _ := m["fields"].([]any)
+	if v, ok := m["fields"]; ok && list == nil && v != "" {
+		return e, fmt.Errorf("%s: fields must be a list", e.Name)
+	}
+
+	seen, params := map[string]bool{}, map[string]bool{}
+
+	for i, item := range list {
+		fm, ok := item.(map[string]any)
+		if !ok {
+			return e, fmt.Errorf("%s: fields[%d] must be a mapping", e.Name, i)
+		}
+
+		f := field{}
+		f.Name, _ = fm["name"].(string)
+		f.Type, _ = fm["type"].(string)
+		f.Description, _ = fm["description"].(string)
+
+		if f.Name == "" || f.Name == keyEventID || seen[f.Name] || paramName(f.Name) == "" || params[paramName(f.Name)] {
+			return e, fmt.Errorf("%s: fields[%d]: invalid or duplicate name %q", e.Name, i, f.Name)
+		}
+
+		if _, ok := fieldTypes[f.Type]; !ok {
+			return e, fmt.Errorf("%s: field %s: unknown type %q", e.Name, f.Name, f.Type)
+		}
+
+		seen[f.Name], params[paramName(f.Name)] = true, true
+		e.Fields = append(e.Fields, f)
+	}
+
+	return e, nil
+}
+
+// snakeCase turns a Go name into a lower snake-case ID: OrderPlaced -> order_placed,
+// HTTPRequestFailed -> http_request_failed.
+func snakeCase(name string) string {
+	var b strings.Builder
+
+	r := []rune(name)
+	for i, c := range r {
+		if unicode.IsUpper(c) && i > 0 &&
+			(unicode.IsLower(r[i-1]) || (i+1 < len(r) && unicode.IsLower(r[i+1]))) {
+			b.WriteByte('_')
+		}
+
+		b.WriteRune(unicode.ToLower(c))
+	}
+
+	return b.String()
+}
+
+// initialisms are upper-cased in parameter names, following Go naming conventions.
+var initialisms = map[string]bool{
+	"api": true, "db": true, "http": true, "id": true, "ip": true, "json": true,
+	"sql": true, "uri": true, "url": true, "uuid": true,
+}
+
+// reservedParams are the other parameters of a generated function and the packages it
+// imports; fields with these names get a "Value" suffix instead of shadowing them.
+var reservedParams = map[string]bool{
+	"ctx": true, "l": true, "err": true,
+	"context": true, "contract": true, "fields": true, "time": true,
+}
+
+// paramName turns a log key into a Go parameter name: order_id -> orderID. It returns ""
+// when the key cannot be turned into an identifier.
+func paramName(key string) string {
+	var b strings.Builder
+
+	words := strings.FieldsFunc(key, func(r rune) bool { return r == '_' || r == '-' || r == '.' })
+	for i, w := range words {
+		lower := strings.ToLower(w)
+
+		switch {
+		case i == 0:
+			b.WriteString(lower)
+		case initialisms[lower]:
+			b.WriteString(strings.ToUpper(lower))
+		default:
+			b.WriteString(strings.ToUpper(lower[:1]) + lower[1:])
+		}
+	}
+
+	name := b.String()
+
+	switch {
+	case !token.IsIdentifier(name) && !token.IsKeyword(name):
+		return ""
+	case token.IsKeyword(name), reservedParams[name]:
+		return name + "Value"
+	default:
+		return name
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestDecodeSchema covers defaults, explicit values and every validation rule.
+func TestDecodeSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    *schema
+		wantErr string
+	}{
+		{name: "defaults", src: "events:\n  - name: CacheWarmed\n", want: &schema{Events: []event{
+			{Name: "CacheWarmed", ID: "cache_warmed", Level: "info", Message: "cache warmed"},
+		}}},
+		{name: "explicit", src: `package: shop
+events:
+  - name: PaymentFailed
+    id: payment.failed
+    level: error
+    message: payment failed
+    description: Rejected.
+    fields:
+      - name: order_id
+        type: string
+        description: Public number.
+      - name: type
+        type: int
+`, want: &schema{Package: "shop", Events: []event{{
+			Name: "PaymentFailed", ID: "payment.failed", Level: "error", Message: "payment failed",
+			Description: "Rejected.",
+			Fields: []field{
+				{Name: "order_id", Type: "string", Description: "Public number."},
+				{Name: "type", Type: "int"},
+			},
+		}}}},
+		{name: "empty fields", src: "events:\n  - name: A\n    fields: []\n", want: &schema{Events: []event{
+			{Name: "A", ID: "a", Level: "info", Message: "a"},
+		}}},
+		{name: "top level list", src: "- a\n", wantErr: "top level must be a mapping"},
+		{name: "no events", src: "package: x\n", wantErr: `"events" must be a non-empty list`},
+		{name: "event not a mapping", src: "events:\n  - a\n", wantErr: "events[0] must be a mapping"},
+		{name: "unexported name", src: "events:\n  - name: orderPlaced\n", wantErr: "exported Go identifier"},
+		{name: "name not a string", src: "events:\n  - name: [A]\n", wantErr: "name must be a string"},
+		{name: "bad level", src: "events:\n  - name: A\n    level: fatal\n", wantErr: `level "fatal"`},
+		{name: "duplicate event", src: "events:\n  - name: A\n  - name: B\n    id: a\n", wantErr: "duplicate event"},
+		{name: "fields not a list", src: "events:\n  - name: A\n    fields: x\n", wantErr: "fields must be a list"},
+		{name: "field not a mapping", src: "events:\n  - name: A\n    fields: [x]\n", wantErr: "fields[0] must be a mapping"},
+		{name: "unknown type", src: "events:\n  - name: A\n    fields:\n      - name: x\n        type: uint\n", wantErr: `unknown type "uint"`},
+		{name: "event_id field", src: "events:\n  - name: A\n    fields:\n      - name: event_id\n        type: string\n", wantErr: `invalid or duplicate name "event_id"`},
+		{name: "same parameter", src: "events:\n  - name: A\n    fields:\n      - name: order_id\n        type: string\n      - name: order.id\n        type: string\n", wantErr: `invalid or duplicate name "order.id"`},
+		{name: "no identifier", src: "events:\n  - name: A\n    fields:\n      - name: \"1x\"\n        type: string\n", wantErr: `invalid or duplicate name "1x"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseYAML(tt.src)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			got, err := decodeSchema(doc)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSnakeCase ensures Go names map to stable lower snake-case IDs.
+func TestSnakeCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"OrderPlaced":       "order_placed",
+		"HTTPRequestFailed": "http_request_failed",
+		"UserID":            "user_id",
+		"A":                 "a",
+		"CacheV2Warmed":     "cache_v2_warmed",
+	} {
+		if got := snakeCase(name); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// TestParamName ensures keys become identifiers that cannot shadow other parameters or imports.
+func TestParamName(t *testing.T) {
+	for key, want := range map[string]string{
+		"order_id":    "orderID",
+		"http.status": "httpStatus",
+		"user-name":   "userName",
+		"took":        "took",
+		"type":        "typeValue",
+		"ctx":         "ctxValue",
+		"l":           "lValue",
+		"err":         "errValue",
+		"context":     "contextValue",
+		"contract":    "contractValue",
+		"fields":      "fieldsValue",
+		"time":        "timeValue",
+		"1x":          "",
+		"_":           "",
+	} {
+		if got := paramName(key); got != want {
+			t.Errorf("paramName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
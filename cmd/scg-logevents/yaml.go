@@ -0,0 +1,383 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// This file implements the YAML subset used by event schemas: block mappings and
+// sequences, plain and quoted scalars, "|" and ">" block scalars, flow sequences of
+// scalars ([a, b]) and comments. Anchors, tags, flow mappings and multi-document streams
+// are not supported. Scalars are returned as strings; typing is left to the schema.
+
+type yamlLine struct {
+	num    int // 1-based line number, for errors
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// parseYAML returns the document as nested map[string]any, []any and string values.
+func parseYAML(src string) (any, error) {
+	p := &yamlParser{}
+
+	for i, raw := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(raw, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+
+		text := strings.TrimRight(raw, " ")
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+		p.lines = append(p.lines, yamlLine{num: i + 1, indent: indent, text: text[indent:]})
+	}
+
+	p.skipBlank()
+
+	if p.done() {
+		return map[string]any{}, nil
+	}
+
+	v, err := p.node(p.lines[p.pos].indent)
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipBlank()
+
+	if !p.done() {
+		l := p.lines[p.pos]
+
+		return nil, fmt.Errorf("line %d: unexpected indentation", l.num)
+	}
+
+	return v, nil
+}
+
+func (p *yamlParser) done() bool {
+	return p.pos >= len(p.lines)
+}
+
+// skipBlank moves past empty and comment-only lines.
+func (p *yamlParser) skipBlank() {
+	for !p.done() {
+		t := p.lines[p.pos].text
+		if t != "" && !strings.HasPrefix(t, "#") && t != "---" {
+			return
+		}
+
+		p.pos++
+	}
+}
+
+// node parses the mapping or sequence starting at the current line, indented by indent.
+func (p *yamlParser) node(indent int) (any, error) {
+	if isSeqItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+
+	return p.mapping(indent)
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) sequence(indent int) ([]any, error) {
+	out := []any{}
+
+	for p.skipBlank(); !p.done(); p.skipBlank() {
+		l := p.lines[p.pos]
+		if l.indent != indent || !isSeqItem(l.text) {
+			if l.indent > indent {
+				return nil, fmt.Errorf("line %d: unexpected indentation", l.num)
+			}
+
+			break
+		}
+
+		rest := strings.TrimLeft(strings.TrimPrefix(l.text, "-"), " ")
+
+		switch {
+		case rest == "" || strings.HasPrefix(rest, "#"):
+			p.pos++
+			p.skipBlank()
+
+			if p.done() || p.lines[p.pos].indent <= indent {
+				out = append(out, "")
+
+				continue
+			}
+
+			v, err := p.node(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, v)
+		case isMappingEntry(rest) || isSeqItem(rest):
+			// "- key: value" opens a mapping whose entries are aligned with key.
+			p.lines[p.pos] = yamlLine{num: l.num, indent: indent + len(l.text) - len(rest), text: rest}
+
+			v, err := p.node(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, v)
+		default:
+			v, err := p.scalar(rest, l.num)
+			if err != nil {
+				return nil, err
+			}
+
+			p.pos++
+			out = append(out, v)
+		}
+	}
+
+	return out, nil
+}
+
+func (p *yamlParser) mapping(indent int) (map[string]any, error) {
+	out := map[string]any{}
+
+	for p.skipBlank(); !p.done(); p.skipBlank() {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+
+		if l.indent > indent || isSeqItem(l.text) {
+			return nil, fmt.Errorf("line %d: unexpected indentation", l.num)
+		}
+
+		key, rest, ok := splitMappingEntry(l.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", l.num)
+		}
+
+		if _, dup := out[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", l.num, key)
+		}
+
+		p.pos++
+
+		v, err := p.value(indent, rest, l.num)
+		if err != nil {
+			return nil, err
+		}
+
+		out[key] = v
+	}
+
+	return out, nil
+}
+
+// value parses what follows "key:" on a line of a mapping indented by indent.
+func (p *yamlParser) value(indent int, rest string, num int) (any, error) {
+	switch {
+	case rest == "|" || rest == ">" || rest == "|-" || rest == ">-":
+		return p.blockScalar(indent, rest), nil
+	case rest != "":
+		return p.scalar(rest, num)
+	}
+
+	p.skipBlank()
+
+	if p.done() {
+		return "", nil
+	}
+
+	next := p.lines[p.pos]
+
+	switch {
+	case next.indent > indent:
+		return p.node(next.indent)
+	case next.indent == indent && isSeqItem(next.text):
+		// A sequence may sit at the same indentation as its key.
+		return p.sequence(indent)
+	default:
+		return "", nil
+	}
+}
+
+// blockScalar reads the lines indented deeper than indent: "|" keeps line breaks, ">"
+// folds them into spaces. The trailing newline is dropped in both cases.
+func (p *yamlParser) blockScalar(indent int, style string) string {
+	var lines []string
+
+	base := -1
+
+	for ; !p.done(); p.pos++ {
+		l := p.lines[p.pos]
+		if l.text != "" && l.indent <= indent {
+			break
+		}
+
+		if l.text != "" && base < 0 {
+			base = l.indent
+		}
+
+		if l.text == "" {
+			lines = append(lines, "")
+		} else {
+			lines = append(lines, strings.Repeat(" ", l.indent-base)+l.text)
+		}
+	}
+
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if strings.HasPrefix(style, ">") {
+		return strings.Join(lines, " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func isMappingEntry(text string) bool {
+	_, _, ok := splitMappingEntry(text)
+
+	return ok
+}
+
+// splitMappingEntry splits "key: value" (or "key:"), honouring quoted keys.
+func splitMappingEntry(text string) (string, string, bool) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := strings.IndexByte(text[1:], text[0])
+		if end < 0 || !strings.HasPrefix(text[end+2:], ":") {
+			return "", "", false
+		}
+
+		rest := text[end+3:]
+		if rest != "" && rest[0] != ' ' {
+			return "", "", false
+		}
+
+		return text[1 : end+1], stripComment(strings.TrimSpace(rest)), true
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			key := strings.TrimSpace(text[:i])
+			if key == "" {
+				return "", "", false
+			}
+
+			return key, stripComment(strings.TrimSpace(text[i+1:])), true
+		}
+	}
+
+	return "", "", false
+}
+
+// stripComment removes a trailing " # comment" outside quotes.
+func stripComment(s string) string {
+	if strings.HasPrefix(s, "#") {
+		return ""
+	}
+
+	var quote byte
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && i > 0 && s[i-1] == ' ':
+			return strings.TrimSpace(s[:i])
+		}
+	}
+
+	return s
+}
+
+// scalar decodes a plain, quoted or flow-sequence scalar.
+func (p *yamlParser) scalar(s string, num int) (any, error) {
+	s = stripComment(s)
+
+	switch {
+	case s == "":
+		return "", nil
+	case s[0] == '"':
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid double-quoted string %s", num, s)
+		}
+
+		return v, nil
+	case s[0] == '\'':
+		if len(s) < 2 || s[len(s)-1] != '\'' {
+			return nil, fmt.Errorf("line %d: invalid single-quoted string %s", num, s)
+		}
+
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case s[0] == '[':
+		if s[len(s)-1] != ']' {
+			return nil, fmt.Errorf("line %d: unterminated flow sequence", num)
+		}
+
+		items, ok := splitFlow(s[1 : len(s)-1])
+		if !ok {
+			return nil, fmt.Errorf("line %d: unterminated quote in flow sequence", num)
+		}
+
+		out := []any{}
+
+		for _, item := range items {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+
+			v, err := p.scalar(item, num)
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, v)
+		}
+
+		return out, nil
+	case s[0] == '{' || s[0] == '&' || s[0] == '*' || s[0] == '!':
+		return nil, fmt.Errorf("line %d: unsupported YAML syntax %q", num, s)
+	default:
+		return s, nil
+	}
+}
+
+// splitFlow splits the items of a flow sequence on commas outside quotes. It reports false
+// when a quote is not closed.
+func splitFlow(s string) ([]string, bool) {
+	var (
+		items []string
+		quote byte
+		start int
+	)
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote == '"' && c == '\\':
+			i++ // skip the escaped character
+		case quote != 0:
+			if c == quote {
+				quote = 0 // '' inside single quotes closes and reopens the quote
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(items, s[start:]), quote == 0
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseYAML covers the supported subset and the errors for unsupported input.
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    any
+		wantErr string
+	}{
+		{name: "empty", src: "# only a comment\n", want: map[string]any{}},
+		{name: "scalars", src: "a: b\nc: \"d: e\"\nf: 'it''s'\ng: h # comment\n", want: map[string]any{
+			"a": "b", "c": "d: e", "f": "it's", "g": "h",
+		}},
+		{name: "nested mapping", src: "a:\n  b:\n    c: d\n", want: map[string]any{
+			"a": map[string]any{"b": map[string]any{"c": "d"}},
+		}},
+		{name: "sequence of mappings", src: "items:\n  - name: x\n    type: int\n  - name: y\n", want: map[string]any{
+			"items": []any{map[string]any{"name": "x", "type": "int"}, map[string]any{"name": "y"}},
+		}},
+		{name: "sequence at key indentation", src: "items:\n- a\n- b\n", want: map[string]any{
+			"items": []any{"a", "b"},
+		}},
+		{name: "flow sequence", src: "a: [x, 'y', \"z\"]\nb: []\n", want: map[string]any{
+			"a": []any{"x", "y", "z"}, "b": []any{},
+		}},
+		{name: "flow sequence with quoted commas", src: `a: ["a,b", 'c, d', "e\",f"]`, want: map[string]any{
+			"a": []any{"a,b", "c, d", `e",f`},
+		}},
+		{name: "literal block", src: "a: |\n  one\n    two\n\nb: c\n", want: map[string]any{
+			"a": "one\n  two", "b": "c",
+		}},
+		{name: "folded block", src: "a: >\n  one\n  two\n", want: map[string]any{"a": "one two"}},
+		{name: "crlf", src: "a: b\r\nc: d\r\n", want: map[string]any{"a": "b", "c": "d"}},
+		{name: "tab indentation", src: "a:\n\tb: c\n", wantErr: "tabs are not allowed"},
+		{name: "duplicate key", src: "a: b\na: c\n", wantErr: `duplicate key "a"`},
+		{name: "bad indentation", src: "a: b\n  c: d\n", wantErr: "unexpected indentation"},
+		{name: "missing colon", src: "a\n", wantErr: `expected "key: value"`},
+		{name: "flow mapping", src: "a: {b: c}\n", wantErr: "unsupported YAML syntax"},
+		{name: "anchor", src: "a: &x b\n", wantErr: "unsupported YAML syntax"},
+		{name: "unterminated flow", src: "a: [b, c\n", wantErr: "unterminated flow sequence"},
+		{name: "unterminated quote in flow", src: `a: ["b, c]`, wantErr: "unterminated quote"},
+		{name: "bad double quote", src: `a: "b`, wantErr: "invalid double-quoted string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML(tt.src)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -17,6 +17,8 @@ import "context"
 // backend-specific way. This library omits the error field when err is nil.
 //
 // All methods accept additional key-value pairs (structured logging). Keys must be strings.
+// A slog.Attr (e.g. from package fields) may be passed instead of a pair, and the attributes
+// of a group slog.Value are inlined. A pair with a non-string key is logged as "!BADKEY".
 // Values can be of any type but should be JSON-serializable for best results.
 //
 // For returns a logger derived from the given context. If the context contains
@@ -0,0 +1,4 @@
+// Package events shows typed event loggers generated by cmd/scg-logevents from events.yaml.
+package events
+
+//go:generate go run github.com/next-trace/scg-logger/cmd/scg-logevents -in events.yaml -out events_gen.go -doc EVENTS.md
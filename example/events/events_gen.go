@@ -0,0 +1,53 @@
+// Code generated by scg-logevents from events.yaml. DO NOT EDIT.
+
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/next-trace/scg-logger/contract"
+	"github.com/next-trace/scg-logger/fields"
+)
+
+// KeyEventID is the field carrying the stable ID of every event below.
+const KeyEventID = "event_id"
+
+// Event IDs.
+const (
+	EventOrderPlaced   = "order_placed"
+	EventPaymentFailed = "payment.failed"
+	EventCacheWarmed   = "cache_warmed"
+)
+
+// LogOrderPlaced logs the order_placed event at info level.
+//
+// A customer placed an order and the payment was authorized.
+func LogOrderPlaced(ctx context.Context, l contract.Logger, orderID string, amount int64, items int) {
+	l.InfoCtx(ctx, "order placed",
+		fields.String(KeyEventID, EventOrderPlaced),
+		fields.String("order_id", orderID),
+		fields.Int64("amount", amount),
+		fields.Int("items", items),
+	)
+}
+
+// LogPaymentFailed logs the payment.failed event at error level.
+//
+// The payment provider rejected or did not answer the charge.
+// Retries are scheduled by the billing worker.
+func LogPaymentFailed(ctx context.Context, l contract.Logger, err error, orderID string, provider string, took time.Duration) {
+	l.ErrorCtx(ctx, "payment failed", err,
+		fields.String(KeyEventID, EventPaymentFailed),
+		fields.String("order_id", orderID),
+		fields.String("provider", provider),
+		fields.Duration("took", took),
+	)
+}
+
+// LogCacheWarmed logs the cache_warmed event at debug level.
+func LogCacheWarmed(ctx context.Context, l contract.Logger) {
+	l.DebugCtx(ctx, "cache warmed",
+		fields.String(KeyEventID, EventCacheWarmed),
+	)
+}
@@ -41,7 +41,7 @@ func main() {
 	l := scglogger.New(
 		scglogger.WithService("demo"),
 		scglogger.WithLevel("debug"),
-		// scglogger.WithPretty(true), // Uncomment to see text output.
+		// scglogger.WithFormat(scglogger.FormatText), // Uncomment to see text output.
 		// scglogger.WithCaller(true), // Uncomment to include source info.
 	)
 	// Optional: also set as slog default if you want third-party libs to use it.
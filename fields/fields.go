@@ -0,0 +1,76 @@
+// Package fields provides typed constructors for structured log fields.
+//
+// Each constructor returns a slog.Attr that can be passed to any contract.Logger method in
+// place of a key-value pair, mixed freely with loose pairs:
+//
+//	l.InfoCtx(ctx, "order placed", fields.String("order_id", id), fields.Int("items", n), "coupon", code)
+//
+// Typed fields are appended to the record as they are, without the key/value normalization
+// applied to loose pairs, and a misplaced key or value is a compile error instead of a
+// garbled record.
+package fields
+
+import (
+	"log/slog"
+	"time"
+)
+
+// KeyErr is the key used by Err. It differs from the built-in "error" key written by
+// ErrorCtx so both can appear on one record.
+const KeyErr = "err"
+
+// String returns a string field.
+func String(key, value string) slog.Attr {
+	return slog.String(key, value)
+}
+
+// Int returns an int field.
+func Int(key string, value int) slog.Attr {
+	return slog.Int(key, value)
+}
+
+// Int64 returns an int64 field.
+func Int64(key string, value int64) slog.Attr {
+	return slog.Int64(key, value)
+}
+
+// Bool returns a bool field.
+func Bool(key string, value bool) slog.Attr {
+	return slog.Bool(key, value)
+}
+
+// Duration returns a time.Duration field.
+func Duration(key string, value time.Duration) slog.Attr {
+	return slog.Duration(key, value)
+}
+
+// Time returns a time.Time field.
+func Time(key string, value time.Time) slog.Attr {
+	return slog.Time(key, value)
+}
+
+// Err returns the error message under KeyErr, or an empty field (omitted from the output)
+// when err is nil.
+func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+
+	return slog.String(KeyErr, err.Error())
+}
+
+// Any returns a field for an arbitrary value, encoded by the output format.
+func Any(key string, value any) slog.Attr {
+	return slog.Any(key, value)
+}
+
+// Group returns a field nesting attrs under key. An empty group is omitted from the output.
+func Group(key string, attrs ...slog.Attr) slog.Attr {
+	return slog.Attr{Key: key, Value: slog.GroupValue(attrs...)}
+}
+
+// Object returns a field for a value that renders itself with LogValue, resolved when the
+// record is written.
+func Object(key string, value slog.LogValuer) slog.Attr {
+	return slog.Any(key, value)
+}
@@ -0,0 +1,69 @@
+package fields_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-logger/fields"
+	"github.com/next-trace/scg-logger/logger"
+)
+
+type user struct {
+	id   string
+	name string
+}
+
+func (u user) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("id", u.id), slog.String("name", u.name))
+}
+
+func TestFieldsAreWrittenAsTyped(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf))
+	l.InfoCtx(t.Context(), "typed",
+		fields.String("s", "v"),
+		fields.Int("i", 1),
+		fields.Int64("i64", 2),
+		fields.Bool("b", true),
+		fields.Duration("d", time.Second),
+		fields.Time("t", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+		fields.Err(errors.New("boom")),
+		fields.Err(nil),
+		fields.Any("a", []int{1, 2}),
+		fields.Group("g", fields.String("inner", "x")),
+		fields.Object("user", user{id: "u1", name: "ann"}),
+		"loose", "pair",
+	)
+
+	m := map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("unmarshal: %v line=%s", err, buf.String())
+	}
+
+	checks := map[string]any{
+		"s": "v", "i": float64(1), "i64": float64(2), "b": true, "d": float64(time.Second),
+		"t": "2024-01-02T03:04:05Z", fields.KeyErr: "boom", "loose": "pair",
+	}
+	for k, want := range checks {
+		if m[k] != want {
+			t.Fatalf("%s: got %v, want %v (line=%s)", k, m[k], want, buf.String())
+		}
+	}
+
+	if g, _ := m["g"].(map[string]any); g["inner"] != "x" {
+		t.Fatalf("expected nested group: %s", buf.String())
+	}
+
+	if u, _ := m["user"].(map[string]any); u["id"] != "u1" {
+		t.Fatalf("expected LogValuer to be resolved: %s", buf.String())
+	}
+
+	if _, ok := m["kv_error"]; ok {
+		t.Fatalf("expected typed fields not to be treated as loose pairs: %s", buf.String())
+	}
+}
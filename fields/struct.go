@@ -0,0 +1,315 @@
+package fields
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Redacted replaces the value of fields tagged log:"redact".
+const Redacted = "[REDACTED]"
+
+// cycleValue replaces a value that refers back to one of its parents.
+const cycleValue = "<cycle>"
+
+// Struct returns a field rendering v with Tagged.
+func Struct(key string, v any) slog.Attr {
+	return slog.Any(key, Tagged(v))
+}
+
+// Tagged returns a slog.LogValuer rendering v, typically a struct or a pointer to one,
+// according to its log struct tags:
+//
+//	type User struct {
+//	    ID       string `log:"id"`
+//	    Email    string `log:"email,redact"`
+//	    Password string `log:"-"`
+//	    Team     *Team  `log:"team,omitempty"`
+//	}
+//
+// The tag holds the attribute name (the field name when empty) followed by options:
+// "redact" writes Redacted instead of the value and "omitempty" skips zero values;
+// log:"redact" and log:"omitempty" alone keep the field name. "-" skips the field, as are
+// unexported fields. Untagged embedded structs are inlined.
+//
+// Nested structs and maps become groups, slices and arrays lists; pointers are followed and
+// a value referring back to one of its parents is written as "<cycle>". Values implementing
+// slog.LogValuer or error below the top level render themselves. The field plan of each
+// struct type is computed once and cached.
+//
+// A type can log itself this way with
+//
+//	func (u User) LogValue() slog.Value { return fields.Tagged(u).LogValue() }
+func Tagged(v any) slog.LogValuer {
+	return tagged{v: v}
+}
+
+type tagged struct {
+	v any
+}
+
+func (t tagged) LogValue() slog.Value {
+	var w walker
+
+	return w.value(reflect.ValueOf(t.v), true)
+}
+
+// fieldPlan describes how one struct field is logged.
+type fieldPlan struct {
+	index     int
+	name      string
+	redact    bool
+	omitEmpty bool
+	inline    bool
+}
+
+// plans caches []fieldPlan per struct type.
+var plans sync.Map
+
+func planFor(t reflect.Type) []fieldPlan {
+	if p, ok := plans.Load(t); ok {
+		return p.([]fieldPlan) //nolint:forcetypeassert // only []fieldPlan is stored.
+	}
+
+	var p []fieldPlan
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+
+		tag, hasTag := sf.Tag.Lookup("log")
+		if tag == "-" {
+			continue
+		}
+
+		fp := fieldPlan{index: i, name: sf.Name}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		switch name {
+		case "redact", "omitempty":
+			opts = name + "," + opts
+		case "":
+		default:
+			fp.name = name
+		}
+
+		for o := range strings.SplitSeq(opts, ",") {
+			fp.redact = fp.redact || o == "redact"
+			fp.omitEmpty = fp.omitEmpty || o == "omitempty"
+		}
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		fp.inline = sf.Anonymous && !hasTag && ft.Kind() == reflect.Struct
+		if !sf.IsExported() && !fp.inline {
+			continue
+		}
+
+		p = append(p, fp)
+	}
+
+	actual, _ := plans.LoadOrStore(t, p)
+
+	return actual.([]fieldPlan) //nolint:forcetypeassert // only []fieldPlan is stored.
+}
+
+var (
+	logValuerType = reflect.TypeFor[slog.LogValuer]()
+	errorType     = reflect.TypeFor[error]()
+	timeType      = reflect.TypeFor[time.Time]()
+	durationType  = reflect.TypeFor[time.Duration]()
+)
+
+// walker converts one value, tracking the references on the current path.
+type walker struct {
+	path map[uintptr]bool
+}
+
+// enter marks a reference as being walked and reports false when it already is.
+func (w *walker) enter(p uintptr) bool {
+	if w.path == nil {
+		w.path = map[uintptr]bool{}
+	}
+
+	if w.path[p] {
+		return false
+	}
+
+	w.path[p] = true
+
+	return true
+}
+
+//nolint:cyclop // one case per kind.
+func (w *walker) value(v reflect.Value, top bool) slog.Value {
+	if !v.IsValid() {
+		return slog.AnyValue(nil)
+	}
+
+	if !top && v.CanInterface() {
+		if s, ok := w.special(v); ok {
+			return s
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return slog.AnyValue(nil)
+		}
+
+		if v.Kind() == reflect.Interface {
+			return w.value(v.Elem(), top)
+		}
+
+		if !w.enter(v.Pointer()) {
+			return slog.StringValue(cycleValue)
+		}
+		defer delete(w.path, v.Pointer())
+
+		return w.value(v.Elem(), top)
+	case reflect.Struct:
+		return slog.GroupValue(w.structAttrs(v)...)
+	case reflect.Map:
+		if v.IsNil() {
+			return slog.AnyValue(nil)
+		}
+
+		if !w.enter(v.Pointer()) {
+			return slog.StringValue(cycleValue)
+		}
+		defer delete(w.path, v.Pointer())
+
+		return slog.GroupValue(w.mapAttrs(v)...)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return slog.AnyValue(nil)
+			}
+
+			if v.Type().Elem().Kind() == reflect.Uint8 {
+				return slog.AnyValue(v.Bytes())
+			}
+
+			if v.Len() > 0 {
+				if !w.enter(v.Pointer()) {
+					return slog.StringValue(cycleValue)
+				}
+				defer delete(w.path, v.Pointer())
+			}
+		}
+
+		list := make([]any, v.Len())
+		for i := range list {
+			list[i] = plain(w.value(v.Index(i), false))
+		}
+
+		return slog.AnyValue(list)
+	default:
+		if !v.CanInterface() {
+			return slog.StringValue(fmt.Sprint(v))
+		}
+
+		return slog.AnyValue(v.Interface())
+	}
+}
+
+// special handles types that render themselves or have a dedicated slog kind.
+func (w *walker) special(v reflect.Value) (slog.Value, bool) {
+	t := v.Type()
+
+	switch {
+	case t == timeType, t == durationType:
+		return slog.AnyValue(v.Interface()), true
+	case t.Implements(logValuerType) || t.Implements(errorType):
+		if (t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface) && v.IsNil() {
+			return slog.AnyValue(nil), true
+		}
+
+		switch x := v.Interface().(type) {
+		case slog.LogValuer:
+			return slog.AnyValue(x).Resolve(), true
+		case error:
+			return slog.StringValue(x.Error()), true
+		}
+	}
+
+	return slog.Value{}, false
+}
+
+func (w *walker) structAttrs(v reflect.Value) []slog.Attr {
+	plan := planFor(v.Type())
+	attrs := make([]slog.Attr, 0, len(plan))
+
+	for _, fp := range plan {
+		f := v.Field(fp.index)
+
+		if fp.omitEmpty && isEmpty(f) {
+			continue
+		}
+
+		if fp.redact {
+			attrs = append(attrs, slog.String(fp.name, Redacted))
+
+			continue
+		}
+
+		val := w.value(f, false)
+		if fp.inline && val.Kind() == slog.KindGroup {
+			attrs = append(attrs, val.Group()...)
+
+			continue
+		}
+
+		attrs = append(attrs, slog.Attr{Key: fp.name, Value: val})
+	}
+
+	return attrs
+}
+
+func (w *walker) mapAttrs(v reflect.Value) []slog.Attr {
+	attrs := make([]slog.Attr, 0, v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		attrs = append(attrs, slog.Attr{Key: fmt.Sprint(iter.Key()), Value: w.value(iter.Value(), false)})
+	}
+
+	slices.SortFunc(attrs, func(a, b slog.Attr) int { return cmp.Compare(a.Key, b.Key) })
+
+	return attrs
+}
+
+// isEmpty reports whether an omitempty field should be skipped.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// plain converts a value for use inside a list: groups become map[string]any.
+func plain(v slog.Value) any {
+	if v.Kind() != slog.KindGroup {
+		return v.Any()
+	}
+
+	m := make(map[string]any, len(v.Group()))
+	for _, a := range v.Group() {
+		m[a.Key] = plain(a.Value)
+	}
+
+	return m
+}
@@ -0,0 +1,147 @@
+package fields_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/next-trace/scg-logger/fields"
+	"github.com/next-trace/scg-logger/logger"
+)
+
+type audit struct {
+	By string `log:"by"`
+}
+
+type team struct {
+	Name   string `log:"name"`
+	Parent *team  `log:"parent,omitempty"`
+}
+
+type account struct {
+	audit
+
+	ID       string            `log:"id"`
+	Email    string            `log:"email,redact"`
+	Token    string            `log:"redact"`
+	Password string            `log:"-"`
+	Nickname string            `log:"nickname,omitempty"`
+	Team     *team             `log:"team"`
+	Tags     []string          `log:"tags"`
+	Labels   map[string]int    `log:"labels"`
+	Members  []team            `log:"members,omitempty"`
+	Err      error             `log:"err"`
+	Extra    map[string]string `log:",omitempty"`
+	internal string
+}
+
+func logTagged(t *testing.T, v any) map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf))
+	l.InfoCtx(t.Context(), "tagged", fields.Struct("obj", v))
+
+	m := map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("unmarshal: %v line=%s", err, buf.String())
+	}
+
+	obj, ok := m["obj"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected object group: %s", buf.String())
+	}
+
+	return obj
+}
+
+func TestTaggedHonoursTags(t *testing.T) {
+	acc := &account{
+		audit:    audit{By: "admin"},
+		ID:       "a1",
+		Email:    "ann@example.com",
+		Token:    "secret-token",
+		Password: "hunter2",
+		Team:     &team{Name: "core"},
+		Tags:     []string{"vip"},
+		Labels:   map[string]int{"b": 2, "a": 1},
+		Members:  []team{{Name: "x"}},
+		Err:      errors.New("locked"),
+		internal: "hidden",
+	}
+
+	obj := logTagged(t, acc)
+
+	if obj["id"] != "a1" || obj["by"] != "admin" || obj["err"] != "locked" {
+		t.Fatalf("unexpected fields: %v", obj)
+	}
+
+	if obj["email"] != fields.Redacted || obj["Token"] != fields.Redacted {
+		t.Fatalf("expected redacted fields: %v", obj)
+	}
+
+	for _, k := range []string{"Password", "nickname", "internal", "Extra"} {
+		if _, ok := obj[k]; ok {
+			t.Fatalf("expected %s to be skipped: %v", k, obj)
+		}
+	}
+
+	if tm, _ := obj["team"].(map[string]any); tm["name"] != "core" {
+		t.Fatalf("expected nested struct: %v", obj)
+	}
+
+	if lb, _ := obj["labels"].(map[string]any); lb["a"] != float64(1) {
+		t.Fatalf("expected map group: %v", obj)
+	}
+
+	ms, _ := obj["members"].([]any)
+	if len(ms) != 1 {
+		t.Fatalf("expected list of structs: %v", obj)
+	}
+
+	if m, _ := ms[0].(map[string]any); m["name"] != "x" {
+		t.Fatalf("expected list of structs: %v", obj)
+	}
+}
+
+func TestTaggedDetectsCycles(t *testing.T) {
+	root := &team{Name: "root"}
+	root.Parent = root
+
+	obj := logTagged(t, root)
+	if obj["name"] != "root" || obj["parent"] != "<cycle>" {
+		t.Fatalf("expected cycle to be cut: %v", obj)
+	}
+}
+
+type selfLogging struct {
+	Name   string `log:"name"`
+	Secret string `log:"secret,redact"`
+}
+
+func (s selfLogging) LogValue() slog.Value { return fields.Tagged(s).LogValue() }
+
+func TestTaggedAsLogValueMethod(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf))
+	l.InfoCtx(t.Context(), "self", "v", selfLogging{Name: "n", Secret: "s"})
+
+	if !strings.Contains(buf.String(), `"v":{"name":"n","secret":"[REDACTED]"}`) {
+		t.Fatalf("expected the type to render itself through its tags: %s", buf.String())
+	}
+}
+
+func BenchmarkTagged(b *testing.B) {
+	acc := account{ID: "a1", Email: "e", Team: &team{Name: "core"}, Tags: []string{"x"}}
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = fields.Tagged(acc).LogValue()
+	}
+}
@@ -0,0 +1,133 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/next-trace/scg-logger/contract"
+	"github.com/next-trace/scg-logger/logger"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Allocation budgets per call, measured with the default JSON handler writing to io.Discard.
+// The kv slices are built once so that only allocations made by the logger are counted.
+const (
+	budgetDisabled  = 0
+	budgetEnabled   = 0
+	budgetWithTrace = 1 // trace and span IDs share one hex string
+	budgetError     = 0 // errors.New result is reused; err.Error() of a static error does not allocate
+	budgetFor       = 0 // the derived logger is cached on the context fields
+)
+
+var (
+	benchKV  = []any{"user_id", "u-123", "attempt", 3, "cached", true}
+	benchErr = errors.New("boom")
+)
+
+func benchLogger(level string) contract.Logger {
+	return logger.New(logger.WithWriter(io.Discard), logger.WithService("bench"), logger.WithLevel(level))
+}
+
+func tracedContext(tb testing.TB) context.Context {
+	tb.Helper()
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer("bench").Start(context.Background(), "op")
+
+	tb.Cleanup(func() {
+		span.End()
+		_ = tp.Shutdown(context.Background())
+	})
+
+	return ctx
+}
+
+func TestAllocationBudgets(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation budgets are not enforced under the race detector")
+	}
+
+	info := benchLogger("info")
+	ctx := t.Context()
+	traced := tracedContext(t)
+	fieldsCtx := logger.WithFields(ctx, map[string]any{"request_id": "req-1"})
+	info.For(fieldsCtx) // warm the derived logger cache
+
+	tests := []struct {
+		name   string
+		budget float64
+		fn     func()
+	}{
+		{"DebugCtx disabled", budgetDisabled, func() { info.DebugCtx(ctx, "filtered", benchKV...) }},
+		{"InfoCtx", budgetEnabled, func() { info.InfoCtx(ctx, "served", benchKV...) }},
+		{"WarnCtx", budgetEnabled, func() { info.WarnCtx(ctx, "slow", benchKV...) }},
+		{"ErrorCtx", budgetError, func() { info.ErrorCtx(ctx, "failed", benchErr, benchKV...) }},
+		{"InfoCtx with trace", budgetWithTrace, func() { info.InfoCtx(traced, "served", benchKV...) }},
+		{"For(ctx).InfoCtx", budgetFor, func() { info.For(fieldsCtx).InfoCtx(fieldsCtx, "served", benchKV...) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testing.AllocsPerRun(100, tt.fn); got > tt.budget {
+				t.Fatalf("allocation budget exceeded: got %.1f allocs/op, budget %.0f", got, tt.budget)
+			}
+		})
+	}
+}
+
+func BenchmarkDebugCtxDisabled(b *testing.B) {
+	l := benchLogger("info")
+	ctx := b.Context()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		l.DebugCtx(ctx, "filtered", benchKV...)
+	}
+}
+
+func BenchmarkInfoCtx(b *testing.B) {
+	l := benchLogger("info")
+	ctx := b.Context()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		l.InfoCtx(ctx, "served", benchKV...)
+	}
+}
+
+func BenchmarkInfoCtxWithTrace(b *testing.B) {
+	l := benchLogger("info")
+	ctx := tracedContext(b)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		l.InfoCtx(ctx, "served", benchKV...)
+	}
+}
+
+func BenchmarkErrorCtx(b *testing.B) {
+	l := benchLogger("info")
+	ctx := b.Context()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		l.ErrorCtx(ctx, "failed", benchErr, benchKV...)
+	}
+}
+
+func BenchmarkFor(b *testing.B) {
+	l := benchLogger("info")
+	ctx := logger.WithFields(b.Context(), map[string]any{"request_id": "req-1", "tenant": "acme"})
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		l.For(ctx).InfoCtx(ctx, "served", benchKV...)
+	}
+}
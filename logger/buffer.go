@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// recordBufferKey is the zero-sized context key for a request-scoped record buffer.
+type recordBufferKey struct{}
+
+// bufferedRecord keeps the handler a record was built for, so a logger derived with For
+// flushes with its own fields.
+type bufferedRecord struct {
+	handler slog.Handler
+	record  slog.Record
+}
+
+// recordBuffer is a fixed-size ring of records below the logger's level.
+type recordBuffer struct {
+	mu      sync.Mutex
+	ring    []bufferedRecord
+	start   int // index of the oldest record
+	n       int // records held
+	dropped int // records overwritten since the last flush
+	closed  bool
+}
+
+// WithRecordBuffer returns a context in which records below the logger's level are held in
+// memory (up to size records, oldest overwritten first) instead of being dropped. When an
+// error-level record is logged with this context, the held records are written first, in
+// their original order and with their original timestamps. Call the returned function when
+// the scope (e.g. the request) ends to discard whatever is still held.
+func WithRecordBuffer(ctx context.Context, size int) (context.Context, func()) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if size <= 0 {
+		return ctx, func() {}
+	}
+
+	b := &recordBuffer{ring: make([]bufferedRecord, size)}
+
+	return context.WithValue(ctx, recordBufferKey{}, b), b.discard
+}
+
+// bufferFrom returns the record buffer of ctx, or nil.
+func bufferFrom(ctx context.Context) *recordBuffer {
+	b, _ := ctx.Value(recordBufferKey{}).(*recordBuffer)
+
+	return b
+}
+
+// hold stores a copy of r unless the scope has ended.
+func (b *recordBuffer) hold(h slog.Handler, r slog.Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	i := (b.start + b.n) % len(b.ring)
+	if b.n == len(b.ring) {
+		b.start = (b.start + 1) % len(b.ring)
+		b.dropped++
+	} else {
+		b.n++
+	}
+
+	b.ring[i] = bufferedRecord{handler: h, record: r.Clone()}
+}
+
+// flush writes the held records in order and empties the buffer. When records were
+// overwritten, a debug record reporting how many precedes them.
+func (b *recordBuffer) flush(ctx context.Context) {
+	b.mu.Lock()
+
+	held := make([]bufferedRecord, 0, b.n)
+	for i := range b.n {
+		held = append(held, b.ring[(b.start+i)%len(b.ring)])
+	}
+
+	dropped := b.dropped
+	clear(b.ring)
+	b.start, b.n, b.dropped = 0, 0, 0
+
+	b.mu.Unlock()
+
+	if len(held) == 0 {
+		return
+	}
+
+	if dropped > 0 {
+		r := slog.NewRecord(time.Now(), slog.LevelDebug, "buffered records dropped", 0)
+		r.AddAttrs(slog.Int("dropped", dropped))
+		_ = held[0].handler.Handle(ctx, r)
+	}
+
+	for _, e := range held {
+		_ = e.handler.Handle(ctx, e.record)
+	}
+}
+
+// discard drops the held records and stops buffering.
+func (b *recordBuffer) discard() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	clear(b.ring)
+	b.start, b.n, b.dropped = 0, 0, 0
+	b.closed = true
+}
@@ -0,0 +1,88 @@
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-logger/logger"
+)
+
+func TestRecordBufferFlushesOnError(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithLevel("info"))
+
+	ctx, end := logger.WithRecordBuffer(t.Context(), 10)
+	defer end()
+
+	l.DebugCtx(ctx, "step one", "n", 1)
+	time.Sleep(time.Millisecond)
+	l.DebugCtx(ctx, "step two", "n", 2)
+
+	if buf.Len() != 0 {
+		t.Fatalf("expected debug records to be held: %s", buf.String())
+	}
+
+	l.ErrorCtx(ctx, "request failed", errors.New("boom"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected two flushed records and the error: %s", buf.String())
+	}
+
+	first, second := parseJSONLine(t, lines[0]), parseJSONLine(t, lines[1])
+	if first["msg"] != "step one" || second["msg"] != "step two" || first["level"] != "DEBUG" {
+		t.Fatalf("expected held records in original order: %s", buf.String())
+	}
+
+	t1, _ := time.Parse(time.RFC3339Nano, first["time"].(string))
+	t2, _ := time.Parse(time.RFC3339Nano, second["time"].(string))
+
+	if !t1.Before(t2) {
+		t.Fatalf("expected original timestamps to be kept: %s", buf.String())
+	}
+}
+
+func TestRecordBufferDiscardedWhenScopeEnds(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf))
+
+	ctx, end := logger.WithRecordBuffer(t.Context(), 10)
+	l.DebugCtx(ctx, "held then discarded")
+	end()
+	l.DebugCtx(ctx, "after end")
+	l.ErrorCtx(ctx, "late error", nil)
+
+	out := buf.String()
+	if strings.Contains(out, "discarded") || strings.Contains(out, "after end") || !strings.Contains(out, "late error") {
+		t.Fatalf("expected only the error after the scope ended: %s", out)
+	}
+}
+
+func TestRecordBufferKeepsNewestAndReportsDropped(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithLevel("warn"))
+
+	ctx, end := logger.WithRecordBuffer(t.Context(), 2)
+	defer end()
+
+	for _, msg := range []string{"a", "b", "c"} {
+		l.InfoCtx(ctx, msg)
+	}
+
+	l.ErrorCtx(ctx, "failed", nil)
+
+	out := buf.String()
+	if strings.Contains(out, `"msg":"a"`) || !strings.Contains(out, `"msg":"b"`) || !strings.Contains(out, `"msg":"c"`) {
+		t.Fatalf("expected the oldest record to be overwritten: %s", out)
+	}
+
+	if !strings.Contains(out, `"dropped":1`) {
+		t.Fatalf("expected dropped count to be reported: %s", out)
+	}
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/next-trace/scg-logger/contract"
+)
+
+// CanonicalMessage is the message of the summary record written by CanonicalLine.Emit.
+const CanonicalMessage = "canonical-log-line"
+
+// Outcome values of a canonical log line.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// canonicalKey is the zero-sized context key for the request's CanonicalLine.
+type canonicalKey struct{}
+
+// CanonicalLine accumulates fields over the lifetime of a request and emits them as one
+// wide summary record ("canonical log line"). It is safe for concurrent use, and all
+// methods are no-ops on a nil *CanonicalLine, so CanonicalFrom(ctx).Add(...) is always safe.
+type CanonicalLine struct {
+	mu      sync.Mutex
+	start   time.Time
+	keys    []string // insertion order
+	values  map[string]any
+	emitted bool
+}
+
+// WithCanonicalLine starts a canonical log line for the scope of ctx (typically a request).
+func WithCanonicalLine(ctx context.Context) (context.Context, *CanonicalLine) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	c := &CanonicalLine{start: time.Now(), values: map[string]any{}}
+
+	return context.WithValue(ctx, canonicalKey{}, c), c
+}
+
+// CanonicalFrom returns the canonical line of ctx, or nil when none was started.
+func CanonicalFrom(ctx context.Context) *CanonicalLine {
+	if ctx == nil {
+		return nil
+	}
+
+	c, _ := ctx.Value(canonicalKey{}).(*CanonicalLine)
+
+	return c
+}
+
+// Set records key=value, replacing an earlier value for key (e.g. user_tier).
+func (c *CanonicalLine) Set(key string, value any) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(key, value)
+}
+
+// Add increments the integer counter key by delta (e.g. db_queries, cache_hits).
+// A key previously Set to a non-integer value is overwritten with delta.
+func (c *CanonicalLine) Add(key string, delta int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n, _ := c.values[key].(int64)
+	c.set(key, n+delta)
+}
+
+func (c *CanonicalLine) set(key string, value any) {
+	if _, ok := c.values[key]; !ok {
+		c.keys = append(c.keys, key)
+	}
+
+	c.values[key] = value
+}
+
+// Emit writes the accumulated fields, duration_ms and outcome as a single record through l:
+// at info level with outcome=success, or at error level with outcome=error when err is set.
+// Only the first call emits; later calls are ignored.
+func (c *CanonicalLine) Emit(ctx context.Context, l contract.Logger, err error) {
+	if c == nil || l == nil {
+		return
+	}
+
+	c.mu.Lock()
+	if c.emitted {
+		c.mu.Unlock()
+		return
+	}
+
+	c.emitted = true
+
+	const extra = 4 // duration_ms and outcome pairs
+
+	kv := make([]any, 0, len(c.keys)*2+extra)
+	for _, k := range c.keys {
+		kv = append(kv, k, c.values[k])
+	}
+	c.mu.Unlock()
+
+	kv = append(kv, "duration_ms", time.Since(c.start).Milliseconds())
+
+	if err != nil {
+		l.ErrorCtx(ctx, CanonicalMessage, err, append(kv, "outcome", OutcomeError)...)
+		return
+	}
+
+	l.InfoCtx(ctx, CanonicalMessage, append(kv, "outcome", OutcomeSuccess)...)
+}
@@ -0,0 +1,77 @@
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/next-trace/scg-logger/logger"
+)
+
+func TestCanonicalLineAccumulatesAndEmitsOnce(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf))
+	ctx, line := logger.WithCanonicalLine(t.Context())
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Go(func() {
+			logger.CanonicalFrom(ctx).Add("db_queries", 1)
+		})
+	}
+
+	wg.Wait()
+
+	line.Set("user_tier", "gold")
+	line.Add("cache_hits", 2)
+	line.Set("user_tier", "platinum")
+
+	line.Emit(ctx, l, nil)
+	line.Emit(ctx, l, errors.New("ignored"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected a single canonical record: %s", buf.String())
+	}
+
+	m := parseJSONLine(t, lines[0])
+	if m["msg"] != logger.CanonicalMessage || m["db_queries"] != float64(10) || m["cache_hits"] != float64(2) {
+		t.Fatalf("unexpected canonical record: %v", m)
+	}
+
+	if m["user_tier"] != "platinum" || m["outcome"] != logger.OutcomeSuccess || m["duration_ms"] == nil {
+		t.Fatalf("unexpected canonical record: %v", m)
+	}
+
+	if !strings.Contains(lines[0], `"db_queries":10,"user_tier":"platinum","cache_hits":2`) {
+		t.Fatalf("expected fields in insertion order: %s", lines[0])
+	}
+}
+
+func TestCanonicalLineErrorOutcome(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf))
+	ctx, line := logger.WithCanonicalLine(t.Context())
+	line.Emit(ctx, l, errors.New("timeout"))
+
+	m := parseFirstJSONLine(t, buf.String())
+	if m["level"] != "ERROR" || m["outcome"] != logger.OutcomeError || m["error"] != "timeout" {
+		t.Fatalf("expected error canonical record: %v", m)
+	}
+}
+
+func TestCanonicalFromWithoutLineIsNoop(t *testing.T) {
+	line := logger.CanonicalFrom(t.Context())
+	line.Add("db_queries", 1)
+	line.Set("k", "v")
+	line.Emit(t.Context(), logger.New(), nil)
+
+	if line != nil {
+		t.Fatal("expected nil canonical line when none was started")
+	}
+}
@@ -5,15 +5,86 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"time"
+
+	ih "github.com/next-trace/scg-logger/logger/handlers"
+)
+
+// Limits bounds message length, value sizes and attribute count per record.
+// See handlers.Limits for the meaning of each field; the zero value disables all limits.
+type Limits = ih.Limits
+
+// SamplingOptions configures per-message sampling, see handlers.SamplingOptions.
+type SamplingOptions = ih.SamplingOptions
+
+// TraceSamplingOptions configures trace-consistent sampling, see handlers.TraceSamplingOptions.
+type TraceSamplingOptions = ih.TraceSamplingOptions
+
+// Budget caps the records and bytes per second a logger writes, see handlers.NewBudget.
+type Budget = ih.Budget
+
+// BudgetOptions configures a Budget, see handlers.BudgetOptions.
+type BudgetOptions = ih.BudgetOptions
+
+// Encoders maps Go types to value encoders, see handlers.DefaultEncoders.
+type Encoders = ih.Encoders
+
+// Validator checks record keys and value types, see handlers.NewValidator.
+type Validator = ih.Validator
+
+// Schema remaps built-in keys and value formats for a log backend.
+// See handlers.SchemaECS, handlers.SchemaGCP, handlers.SchemaDatadog and handlers.SchemaOTel.
+type Schema = ih.Schema
+
+// DuplicatePolicy decides which attribute wins when a top-level key is set more than once.
+type DuplicatePolicy = ih.DuplicatePolicy
+
+// Duplicate key policies, see handlers.DuplicatePolicy.
+const (
+	DuplicateLastWins  = ih.DuplicateLastWins
+	DuplicateFirstWins = ih.DuplicateFirstWins
+	DuplicatePrefix    = ih.DuplicatePrefix
+	DuplicateAllow     = ih.DuplicateAllow
+)
+
+// Format selects the output encoding of records.
+type Format string
+
+// Supported output formats. Unknown formats fall back to FormatJSON.
+const (
+	FormatJSON   Format = "json"
+	FormatText   Format = "text"
+	FormatLogfmt Format = "logfmt"
+	// FormatConsole is a colorized developer format; colors are disabled when the
+	// writer is not a terminal or NO_COLOR is set.
+	FormatConsole Format = "console"
+	// FormatCBOR writes length-prefixed CBOR records; read them back with
+	// handlers.NewCBORDecoder or the cmd/scg-cbor2json tool.
+	FormatCBOR Format = "cbor"
 )
 
 // Config holds logger configuration.
 type Config struct {
 	Service    string
 	Level      string    // "debug" | "info" | "warn" | "error"
-	Pretty     bool      // text vs JSON
+	Format     Format    // output encoding, default JSON
 	WithCaller bool      // add source info
 	Writer     io.Writer // optional, default stdout
+	Limits     Limits    // optional size limits, zero means unlimited
+
+	DuplicateKeys DuplicatePolicy // resolution of repeated keys, default last-wins
+	KeyPrefix     string          // prefix for renamed keys, default "fields."
+	Schema        Schema          // optional output key mapping, zero keeps slog's keys
+
+	AutoContextFields bool                 // add WithFields/WithAttrs fields from ctx without calling For
+	MessageTemplates  bool                 // render {name} placeholders in messages
+	Extractors        []NamedExtractor     // ctx -> attributes, run on every call; default: OTel trace
+	Sampling          SamplingOptions      // per-message sampling, zero disables it
+	TraceSampling     TraceSamplingOptions // per-trace sampling of low levels, zero disables it
+	DedupWindow       time.Duration        // collapse identical consecutive records, zero disables it
+	Budget            *Budget              // records/bytes per second cap, nil disables it
+	Encoders          *Encoders            // per-type value encoders, nil keeps the format's defaults
+	Validator         *Validator           // key/type rules reported on violation, nil disables it
 }
 
 // Option is a functional option to modify Config.
@@ -29,9 +100,22 @@ func WithLevel(level string) Option {
 	return func(c *Config) { c.Level = level }
 }
 
+// WithFormat selects the output format (FormatJSON, FormatText, FormatLogfmt, FormatConsole, FormatCBOR).
+func WithFormat(format Format) Option {
+	return func(c *Config) { c.Format = format }
+}
+
 // WithPretty toggles human-readable text output (true) vs JSON (false).
+//
+// Deprecated: use WithFormat(FormatText) or WithFormat(FormatJSON).
 func WithPretty(pretty bool) Option {
-	return func(c *Config) { c.Pretty = pretty }
+	return func(c *Config) {
+		if pretty {
+			c.Format = FormatText
+		} else {
+			c.Format = FormatJSON
+		}
+	}
 }
 
 // WithCaller toggles inclusion of caller/source information.
@@ -44,12 +128,102 @@ func WithWriter(w io.Writer) Option {
 	return func(c *Config) { c.Writer = w }
 }
 
+// WithLimits bounds the size of every record (message, values and attribute count).
+func WithLimits(limits Limits) Option {
+	return func(c *Config) { c.Limits = limits }
+}
+
+// WithSchema remaps built-in keys (time, level, msg, source, service, trace_id, span_id, error)
+// and their value formats, e.g. logger.WithSchema(handlers.SchemaECS()).
+func WithSchema(schema Schema) Option {
+	return func(c *Config) { c.Schema = schema }
+}
+
+// WithEncoders renders values by type before the schema is applied, e.g.
+// logger.WithEncoders(handlers.DefaultEncoders()) for output JavaScript consumers can parse.
+func WithEncoders(e *Encoders) Option {
+	return func(c *Config) { c.Encoders = e }
+}
+
+// WithMessageTemplates renders {name} placeholders in messages from the record's fields,
+// e.g. InfoCtx(ctx, "user {user_id} bought {count} items", "user_id", 7, "count", 3) writes
+// "user 7 bought 3 items" with the fields and the template itself as msg_template, a stable
+// key for grouping. Placeholders without a value are kept and listed in msg_template_missing;
+// call-site fields no placeholder refers to are listed in msg_template_unused.
+func WithMessageTemplates(enabled bool) Option {
+	return func(c *Config) { c.MessageTemplates = enabled }
+}
+
+// WithAutoContextFields makes DebugCtx/InfoCtx/WarnCtx/ErrorCtx pick up fields attached with
+// WithFields or WithAttrs from their ctx argument, so forgetting l.For(ctx) no longer drops them.
+// Fields already present on a logger obtained from For(ctx) are not repeated.
+func WithAutoContextFields(enabled bool) Option {
+	return func(c *Config) { c.AutoContextFields = enabled }
+}
+
+// WithSampling limits how often the same (level, message) is written, grouping templated
+// messages by their template (see WithMessageTemplates): per interval the first
+// opts.First records pass, then every opts.Thereafter-th; dropped counts are reported in a
+// periodic summary record, and by Flush for the current interval.
+func WithSampling(opts SamplingOptions) Option {
+	return func(c *Config) { c.Sampling = opts }
+}
+
+// WithTraceSampling keeps or drops records below opts.KeepLevel per trace instead of per
+// record, so a kept trace has all of its debug lines. Without a trace in ctx the decision
+// uses opts.Key, which defaults to the "request_id" field attached with WithFields/WithAttrs.
+func WithTraceSampling(opts TraceSamplingOptions) Option {
+	return func(c *Config) {
+		if opts.Key == nil {
+			opts.Key = FieldString(DefaultSamplingField)
+		}
+
+		c.TraceSampling = opts
+	}
+}
+
+// WithDedup collapses identical consecutive records (same level, message and fields) written
+// within window into one, followed by a "previous message repeated" record with the count
+// and the first and last repeat times; Flush writes a pending one.
+func WithDedup(window time.Duration) Option {
+	return func(c *Config) { c.DedupWindow = window }
+}
+
+// WithBudget enforces a throughput cap, e.g.
+// logger.WithBudget(handlers.NewBudget(handlers.BudgetOptions{RecordsPerSecond: 1000, ErrorReserve: 0.2})).
+// Keep the Budget to read its Dropped counter; records shed are also reported periodically
+// and by Flush.
+func WithBudget(b *Budget) Option {
+	return func(c *Config) { c.Budget = b }
+}
+
+// WithValidator checks every written record (after sampling, dedup and the budget) against
+// key to type rules and required keys, e.g. in tests:
+// logger.WithValidator(handlers.NewValidator(handlers.ValidationOptions{
+// Learn: true, OnViolation: handlers.FailOnViolation(t)})).
+func WithValidator(v *Validator) Option {
+	return func(c *Config) { c.Validator = v }
+}
+
+// WithDuplicatePolicy sets how repeated keys across persistent, context and call-site fields are resolved.
+func WithDuplicatePolicy(policy DuplicatePolicy) Option {
+	return func(c *Config) { c.DuplicateKeys = policy }
+}
+
+// WithKeyPrefix sets the prefix used to rename caller keys that collide with keys the logger
+// writes itself (time, level, msg, source, service when set, and the output keys of the
+// schema, e.g. "message" with SchemaECS) or, with DuplicatePrefix, with each other.
+func WithKeyPrefix(prefix string) Option {
+	return func(c *Config) { c.KeyPrefix = prefix }
+}
+
 // applyOptions builds a Config with defaults then applies options.
 func applyOptions(opts ...Option) Config {
 	cfg := Config{
-		Level:  "info",
-		Pretty: false,
-		Writer: os.Stdout,
+		Level:      "info",
+		Format:     FormatJSON,
+		Writer:     os.Stdout,
+		Extractors: defaultExtractors(),
 	}
 
 	for _, opt := range opts {
@@ -62,9 +236,33 @@ func applyOptions(opts ...Option) Config {
 		cfg.Writer = os.Stdout
 	}
 
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = ih.DefaultKeyPrefix
+	}
+
 	return cfg
 }
 
+// newFormatHandler returns the base handler encoding records in the configured format,
+// writing through the byte budget when one is set.
+func newFormatHandler(cfg Config, opts slog.HandlerOptions) slog.Handler {
+	w := cfg.Budget.Writer(cfg.Writer)
+
+	switch cfg.Format {
+	case FormatText:
+		return ih.Text(w, opts)
+	case FormatLogfmt:
+		return ih.Logfmt(w, opts)
+	case FormatConsole:
+		// Colors depend on the configured writer being a terminal, not on the budget wrapper.
+		return ih.ConsoleColored(w, opts, ih.ColorEnabled(cfg.Writer))
+	case FormatCBOR:
+		return ih.CBOR(w, opts)
+	default:
+		return ih.JSON(w, opts)
+	}
+}
+
 // mapLevel converts the string level to slog.Level. Defaults to info.
 func mapLevel(lvl string) (slog.Level, error) {
 	switch lvl {
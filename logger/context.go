@@ -2,6 +2,9 @@ package logger
 
 import (
 	"context"
+	"log/slog"
+	"slices"
+	"sync/atomic"
 
 	"github.com/next-trace/scg-logger/contract"
 )
@@ -11,11 +14,38 @@ type contextKey struct{}
 
 var loggerKey = contextKey{}
 
-// fieldsContextKey is an unexported string-based key type to avoid collisions for log fields.
-type fieldsContextKey string
+// fieldsContextKey is an unexported key type to avoid collisions for log fields.
+// It is zero-sized so that ctx.Value lookups on the hot path do not allocate.
+type fieldsContextKey struct{}
 
-// logFieldsKey is the private key under which a map[string]interface{} may be stored in ctx.
-var logFieldsKey = fieldsContextKey("log_fields")
+// logFieldsKey is the private key under which a *fieldSet may be stored in ctx.
+var logFieldsKey = fieldsContextKey{}
+
+// fieldSet is the immutable value stored by WithFields and WithAttrs. Attributes are kept
+// in insertion order and built once when the fields are attached, and the logger derived
+// by For is cached so repeated For(ctx) calls on the same base logger are a lookup.
+type fieldSet struct {
+	attrs   []slog.Attr
+	removed []string // keys removed by WithoutFields along the way, see slogLogger.log
+	cache   atomic.Pointer[derivedLogger]
+}
+
+// derivedLogger is a single-entry cache: services usually derive from one base logger.
+type derivedLogger struct {
+	base    *slogLogger
+	derived *slogLogger
+}
+
+// fieldsFrom returns the fields attached to ctx, or nil.
+func fieldsFrom(ctx context.Context) *fieldSet {
+	if ctx == nil {
+		return nil
+	}
+
+	fs, _ := ctx.Value(logFieldsKey).(*fieldSet)
+
+	return fs
+}
 
 // IntoContext stores the Logger in the context.
 func IntoContext(ctx context.Context, l contract.Logger) context.Context {
@@ -40,32 +70,131 @@ func FromContext(ctx context.Context) contract.Logger {
 }
 
 // WithFields returns a context containing structured log fields at a private key.
-// The expected value is a map[string]interface{}; if ctx already has fields, it merges them.
+// If ctx already has fields, it merges them: existing keys keep their position and take the
+// new value, new keys are appended in sorted key order so output is stable across runs.
 // This allows middleware to attach correlation fields (e.g., trace_id) to be picked up by Logger.For.
 func WithFields(ctx context.Context, fields map[string]any) context.Context {
+	if len(fields) == 0 {
+		if ctx == nil {
+			return context.Background()
+		}
+
+		return ctx
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, slog.Any(k, fields[k]))
+	}
+
+	return WithAttrs(ctx, attrs...)
+}
+
+// WithAttrs is the typed, ordered variant of WithFields: attributes are appended in the
+// given order, and an attribute whose key is already attached overrides it in place.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	if len(fields) == 0 {
+	if len(attrs) == 0 {
 		return ctx
 	}
-	var base map[string]any
-	if v := ctx.Value(logFieldsKey); v != nil {
-		if m, ok := v.(map[string]interface{}); ok && m != nil {
-			// copy existing to avoid mutating parent context values
-			base = make(map[string]any, len(m)+len(fields))
-			for k, val := range m {
-				base[k] = val
-			}
+
+	var (
+		merged  []slog.Attr
+		removed []string
+	)
+
+	if parent := fieldsFrom(ctx); parent != nil {
+		// copy existing to avoid mutating parent context values
+		merged = make([]slog.Attr, len(parent.attrs), len(parent.attrs)+len(attrs))
+		copy(merged, parent.attrs)
+
+		removed = slices.DeleteFunc(slices.Clone(parent.removed), func(k string) bool {
+			return slices.ContainsFunc(attrs, func(a slog.Attr) bool { return a.Key == k })
+		})
+	}
+
+	for _, a := range attrs {
+		if i := slices.IndexFunc(merged, func(b slog.Attr) bool { return b.Key == a.Key }); i >= 0 {
+			merged[i] = a
+		} else {
+			merged = append(merged, a)
+		}
+	}
+
+	return context.WithValue(ctx, logFieldsKey, &fieldSet{attrs: merged, removed: removed})
+}
+
+// WithoutFields returns a context in which the given keys are no longer attached,
+// e.g. to stop a sensitive field from reaching logs further down the call graph. This also
+// holds for a logger already derived with For: when it is called with the returned context
+// (or one derived from it) the removed keys are left out.
+func WithoutFields(ctx context.Context, keys ...string) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+
+	parent := fieldsFrom(ctx)
+	if parent == nil || len(keys) == 0 {
+		return ctx
+	}
+
+	kept := make([]slog.Attr, 0, len(parent.attrs))
+	removed := slices.Clone(parent.removed)
+
+	for _, a := range parent.attrs {
+		if !slices.Contains(keys, a.Key) {
+			kept = append(kept, a)
+		} else if !slices.Contains(removed, a.Key) {
+			removed = append(removed, a.Key)
 		}
 	}
-	if base == nil {
-		base = make(map[string]any, len(fields))
+
+	if len(kept) == len(parent.attrs) {
+		return ctx
+	}
+
+	return context.WithValue(ctx, logFieldsKey, &fieldSet{attrs: kept, removed: removed})
+}
+
+// Fields returns a copy of the fields attached to ctx in order, or nil when there are none.
+func Fields(ctx context.Context) []slog.Attr {
+	fs := fieldsFrom(ctx)
+	if fs == nil || len(fs.attrs) == 0 {
+		return nil
 	}
-	for k, val := range fields {
-		base[k] = val
+
+	return slices.Clone(fs.attrs)
+}
+
+// DefaultSamplingField is the context field WithTraceSampling falls back to without a trace.
+const DefaultSamplingField = "request_id"
+
+// FieldString returns a function reading the string form of the context field key, or ""
+// when it is not attached; e.g. as TraceSamplingOptions.Key.
+func FieldString(key string) func(ctx context.Context) string {
+	return func(ctx context.Context) string {
+		fs := fieldsFrom(ctx)
+		if fs == nil {
+			return ""
+		}
+
+		for _, a := range fs.attrs {
+			if a.Key == key {
+				return a.Value.String()
+			}
+		}
+
+		return ""
 	}
-	return context.WithValue(ctx, logFieldsKey, base)
 }
 
 // Example usage:
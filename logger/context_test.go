@@ -0,0 +1,278 @@
+package logger_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-logger/logger"
+	"github.com/next-trace/scg-logger/logger/handlers"
+)
+
+func TestWithFieldsIsDeterministic(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf))
+	ctx := logger.WithFields(t.Context(), map[string]any{"zeta": 1, "alpha": 2, "mid": 3})
+
+	for range 5 {
+		l.For(ctx).InfoCtx(ctx, "ordered")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for _, line := range lines {
+		if !strings.Contains(line, `"alpha":2,"mid":3,"zeta":1`) {
+			t.Fatalf("expected fields in sorted order: %s", line)
+		}
+	}
+}
+
+func TestWithAttrsKeepsInsertionOrderAndOverridesInPlace(t *testing.T) {
+	ctx := logger.WithAttrs(t.Context(), slog.String("request_id", "r1"), slog.Int("attempt", 1))
+	ctx = logger.WithAttrs(ctx, slog.String("user", "ann"), slog.Int("attempt", 2))
+
+	got := logger.Fields(ctx)
+
+	want := []slog.Attr{slog.String("request_id", "r1"), slog.Int("attempt", 2), slog.String("user", "ann")}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected fields: %v", got)
+	}
+
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Fatalf("field %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWithoutFieldsRemovesInChildOnly(t *testing.T) {
+	parent := logger.WithAttrs(t.Context(), slog.String("token", "secret"), slog.String("user", "ann"))
+	child := logger.WithoutFields(parent, "token")
+
+	if got := logger.Fields(child); len(got) != 1 || got[0].Key != "user" {
+		t.Fatalf("expected token removed in child: %v", got)
+	}
+
+	if got := logger.Fields(parent); len(got) != 2 {
+		t.Fatalf("expected parent fields untouched: %v", got)
+	}
+}
+
+func TestFieldsReturnsCopy(t *testing.T) {
+	ctx := logger.WithAttrs(t.Context(), slog.String("user", "ann"))
+
+	got := logger.Fields(ctx)
+	got[0] = slog.String("user", "mallory")
+
+	if logger.Fields(ctx)[0].Value.String() != "ann" {
+		t.Fatal("expected Fields to return a copy")
+	}
+
+	if logger.Fields(t.Context()) != nil {
+		t.Fatal("expected nil fields for a bare context")
+	}
+}
+
+func TestAutoContextFieldsWithoutFor(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithAutoContextFields(true))
+	ctx := logger.WithFields(t.Context(), map[string]any{"request_id": "req-1"})
+	l.InfoCtx(ctx, "no For")
+
+	if !strings.Contains(buf.String(), `"request_id":"req-1"`) {
+		t.Fatalf("expected context field without For: %s", buf.String())
+	}
+}
+
+func TestAutoContextFieldsDeduplicatesWithFor(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithAutoContextFields(true))
+	parent := logger.WithFields(t.Context(), map[string]any{"request_id": "req-1"})
+	child := logger.WithAttrs(parent, slog.String("step", "charge"))
+
+	l.For(parent).InfoCtx(child, "both")
+
+	out := buf.String()
+	if strings.Count(out, `"request_id"`) != 1 || !strings.Contains(out, `"step":"charge"`) {
+		t.Fatalf("expected each context field exactly once: %s", out)
+	}
+}
+
+func TestAutoContextFieldsWithUncomparableValues(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithAutoContextFields(true),
+		logger.WithDuplicatePolicy(logger.DuplicateAllow))
+	parent := logger.WithFields(t.Context(), map[string]any{"tags": []string{"a"}, "meta": map[string]int{"n": 1}})
+	child := logger.WithAttrs(parent, slog.String("step", "charge"))
+
+	l.For(parent).InfoCtx(child, "no panic")
+
+	out := buf.String()
+	if strings.Count(out, `"tags"`) != 1 || strings.Count(out, `"meta"`) != 1 || !strings.Contains(out, `"step":"charge"`) {
+		t.Fatalf("expected slice and map fields exactly once: %s", out)
+	}
+}
+
+func TestAutoContextFieldsDisabledByDefault(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf))
+	ctx := logger.WithFields(t.Context(), map[string]any{"request_id": "req-1"})
+	l.InfoCtx(ctx, "opt-in")
+
+	if strings.Contains(buf.String(), "request_id") {
+		t.Fatalf("did not expect context fields without opting in: %s", buf.String())
+	}
+}
+
+func TestWithLevelOverride(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithLevel("info"))
+	debugCtx := logger.WithLevelOverride(t.Context(), "debug")
+	errorCtx := logger.WithLevelOverride(t.Context(), "error")
+
+	l.DebugCtx(debugCtx, "debug for this request")
+	l.DebugCtx(t.Context(), "debug elsewhere")
+	l.WarnCtx(errorCtx, "warn silenced for this request")
+
+	out := buf.String()
+	if !strings.Contains(out, "debug for this request") {
+		t.Fatalf("expected override to enable debug: %s", out)
+	}
+
+	if strings.Contains(out, "debug elsewhere") || strings.Contains(out, "warn silenced") {
+		t.Fatalf("expected other requests to keep the configured level: %s", out)
+	}
+
+	if _, ok := logger.LevelOverride(logger.WithLevelOverride(t.Context(), "bogus")); ok {
+		t.Fatal("expected invalid level to be ignored")
+	}
+}
+
+func TestTraceSamplingUsesRequestIDField(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithLevel("debug"),
+		logger.WithTraceSampling(logger.TraceSamplingOptions{Ratio: 0.5}))
+
+	kept := 0
+
+	for i := range 100 {
+		ctx := logger.WithAttrs(t.Context(), slog.Int("request_id", i))
+
+		buf.Reset()
+		l.DebugCtx(ctx, "step one")
+		l.DebugCtx(ctx, "step two")
+
+		switch strings.Count(buf.String(), "\n") {
+		case 2:
+			kept++
+		case 0:
+		default:
+			t.Fatalf("request %d: expected all or none of its debug records: %s", i, buf.String())
+		}
+	}
+
+	if kept == 0 || kept == 100 {
+		t.Fatalf("expected requests to be sampled by request_id, kept %d/100", kept)
+	}
+}
+
+func TestWithoutFieldsAppliesToLoggerDerivedWithFor(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf))
+	ctx := logger.WithFields(t.Context(), map[string]any{"password": "s3cret", "request_id": "req-1"})
+	derived := l.For(ctx)
+
+	clean := logger.WithoutFields(ctx, "password")
+	derived.InfoCtx(clean, "removed")
+	derived.InfoCtx(logger.WithAttrs(clean, slog.String("step", "2")), "removed in a child context")
+
+	out := buf.String()
+	if strings.Contains(out, "s3cret") || strings.Count(out, `"request_id":"req-1"`) != 2 {
+		t.Fatalf("expected removed field to be left out by the derived logger: %s", out)
+	}
+
+	buf.Reset()
+	derived.InfoCtx(ctx, "original context")
+
+	if !strings.Contains(buf.String(), `"password":"s3cret"`) {
+		t.Fatalf("expected the field with the original context: %s", buf.String())
+	}
+}
+
+// TestSamplingSummaryHasNoRequestFields ensures a summary covering several requests is not
+// attributed to the request whose record happened to trigger it.
+func TestSamplingSummaryHasNoRequestFields(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithService("svc"),
+		logger.WithSampling(logger.SamplingOptions{Interval: 20 * time.Millisecond, First: 1}))
+
+	ctxA := logger.WithFields(t.Context(), map[string]any{"request_id": "A"})
+	ctxB := logger.WithFields(t.Context(), map[string]any{"request_id": "B", "tenant": "other"})
+
+	for range 3 {
+		l.For(ctxA).WarnCtx(ctxA, "hot")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	l.For(ctxB).InfoCtx(ctxB, "other request") // starts a new interval
+
+	for line := range strings.Lines(buf.String()) {
+		if !strings.Contains(line, handlers.SampledMessage) {
+			continue
+		}
+
+		m := parseJSONLine(t, line)
+		if m["dropped"] != float64(2) || m["service"] != "svc" || m["request_id"] != nil || m["tenant"] != nil {
+			t.Fatalf("expected a summary with the service and no request fields: %s", line)
+		}
+
+		return
+	}
+
+	t.Fatalf("expected a sampling summary: %s", buf.String())
+}
+
+// TestBudgetReportHasNoRequestFields ensures shed reports, which cover the whole logger, are
+// not attributed to the request whose record triggered them.
+func TestBudgetReportHasNoRequestFields(t *testing.T) {
+	var buf bytes.Buffer
+
+	budget := handlers.NewBudget(handlers.BudgetOptions{RecordsPerSecond: 1, ReportInterval: time.Hour})
+	l := logger.New(logger.WithWriter(&buf), logger.WithService("svc"), logger.WithBudget(budget))
+
+	ctxA := logger.WithFields(t.Context(), map[string]any{"request_id": "A"})
+	ctxB := logger.WithFields(t.Context(), map[string]any{"request_id": "B", "tenant": "other"})
+
+	for range 3 {
+		l.For(ctxA).InfoCtx(ctxA, "burst")
+	}
+
+	if err := logger.Flush(ctxB, l.For(ctxB)); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	for line := range strings.Lines(buf.String()) {
+		if !strings.Contains(line, handlers.ShedMessage) {
+			continue
+		}
+
+		m := parseJSONLine(t, line)
+		if m["shed"] != float64(2) || m["service"] != "svc" || m["request_id"] != nil || m["tenant"] != nil {
+			t.Fatalf("expected a shed report with the service and no request fields: %s", line)
+		}
+
+		return
+	}
+
+	t.Fatalf("expected a shed report: %s", buf.String())
+}
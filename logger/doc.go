@@ -2,10 +2,13 @@
 // and helpers to work with contexts and configuration.
 //
 // Key design choices:
-//   - Functional options (WithService/WithLevel/WithPretty/WithCaller/WithWriter) for construction.
+//   - Functional options (WithService/WithLevel/WithFormat/WithCaller/WithWriter) for construction.
 //   - No global logger: inject contract.Logger via context (IntoContext/FromContext), enabling testability.
 //   - Context-aware methods (DebugCtx/InfoCtx/WarnCtx/ErrorCtx) and Logger.For(ctx) to enrich from context.
-//   - OpenTelemetry correlation: trace_id and span_id are appended when a valid span is present.
+//   - Context extractors: ctx -> attribute functions run on every call (WithExtractor); the built-in
+//     OpenTelemetry extractor appends trace_id and span_id when a valid span is present.
+//   - Allocation-aware hot path: the level is checked before any argument processing and records are
+//     built from pooled buffers; bench_test.go enforces per-method allocation budgets.
 //   - Handlers: thin wrappers around slog JSON/Text handlers for clear defaults and extensibility.
 //
 // Usage:
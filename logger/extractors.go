@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ExtractorTrace is the registry name of the built-in OpenTelemetry extractor.
+const ExtractorTrace = "otel"
+
+// Extractor derives attributes from the context of a log call and appends them to attrs.
+// Extractors run on every enabled log call, so they should be cheap and must not retain attrs.
+type Extractor func(ctx context.Context, attrs []slog.Attr) []slog.Attr
+
+// NamedExtractor is a registry entry; names let options replace or disable an extractor.
+type NamedExtractor struct {
+	Name    string
+	Extract Extractor
+}
+
+// defaultExtractors is the registry a new Config starts with.
+func defaultExtractors() []NamedExtractor {
+	return []NamedExtractor{{Name: ExtractorTrace, Extract: TraceExtractor}}
+}
+
+// WithExtractor registers fn under name. Registering an existing name replaces that
+// extractor in place, e.g. WithExtractor(logger.ExtractorTrace, custom) swaps the OTel one.
+func WithExtractor(name string, fn Extractor) Option {
+	return func(c *Config) {
+		if fn == nil {
+			return
+		}
+
+		for i := range c.Extractors {
+			if c.Extractors[i].Name == name {
+				c.Extractors[i].Extract = fn
+				return
+			}
+		}
+
+		c.Extractors = append(c.Extractors, NamedExtractor{Name: name, Extract: fn})
+	}
+}
+
+// WithoutExtractor removes the extractor registered under name, e.g.
+// WithoutExtractor(logger.ExtractorTrace) disables trace_id/span_id correlation.
+func WithoutExtractor(name string) Option {
+	return func(c *Config) {
+		kept := c.Extractors[:0:0]
+		for _, e := range c.Extractors {
+			if e.Name != name {
+				kept = append(kept, e)
+			}
+		}
+
+		c.Extractors = kept
+	}
+}
+
+// ContextValueExtractor returns an extractor logging ctx.Value(key) under attrKey when present,
+// e.g. ContextValueExtractor(tenantKey{}, "tenant_id").
+func ContextValueExtractor(key any, attrKey string) Extractor {
+	return func(ctx context.Context, attrs []slog.Attr) []slog.Attr {
+		if v := ctx.Value(key); v != nil {
+			return append(attrs, slog.Any(attrKey, v))
+		}
+
+		return attrs
+	}
+}
+
+// DeadlineExtractor logs the time left before the context deadline as "deadline_in" when
+// the context has one, which helps explain timeouts in downstream calls.
+func DeadlineExtractor(ctx context.Context, attrs []slog.Attr) []slog.Attr {
+	if deadline, ok := ctx.Deadline(); ok {
+		return append(attrs, slog.Duration("deadline_in", time.Until(deadline)))
+	}
+
+	return attrs
+}
@@ -0,0 +1,88 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-logger/logger"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type tenantKey struct{}
+
+func tracedCtx(t *testing.T) context.Context {
+	t.Helper()
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer("test").Start(t.Context(), "op")
+	t.Cleanup(func() { span.End() })
+
+	return ctx
+}
+
+func TestContextValueExtractor(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(
+		logger.WithWriter(&buf),
+		logger.WithExtractor("tenant", logger.ContextValueExtractor(tenantKey{}, "tenant_id")),
+	)
+	ctx := context.WithValue(t.Context(), tenantKey{}, "acme")
+	l.InfoCtx(ctx, "tenant line")
+
+	if m := parseFirstJSONLine(t, buf.String()); m["tenant_id"] != "acme" {
+		t.Fatalf("expected tenant_id from extractor: %v", m)
+	}
+}
+
+func TestWithoutTraceExtractor(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithoutExtractor(logger.ExtractorTrace))
+	l.InfoCtx(tracedCtx(t), "no correlation")
+
+	if m := parseFirstJSONLine(t, buf.String()); m["trace_id"] != nil || m["span_id"] != nil {
+		t.Fatalf("did not expect trace correlation when disabled: %v", m)
+	}
+}
+
+func TestReplaceTraceExtractor(t *testing.T) {
+	var buf bytes.Buffer
+
+	custom := func(_ context.Context, attrs []slog.Attr) []slog.Attr {
+		return append(attrs, slog.String("correlation", "custom"))
+	}
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithExtractor(logger.ExtractorTrace, custom))
+	l.InfoCtx(tracedCtx(t), "replaced")
+
+	m := parseFirstJSONLine(t, buf.String())
+	if m["trace_id"] != nil || m["correlation"] != "custom" {
+		t.Fatalf("expected the built-in extractor to be replaced: %v", m)
+	}
+}
+
+func TestDeadlineExtractor(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithExtractor("deadline", logger.DeadlineExtractor))
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Minute)
+	defer cancel()
+
+	l.InfoCtx(ctx, "with deadline")
+	l.InfoCtx(t.Context(), "without deadline")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+
+	if m := parseJSONLine(t, string(lines[0])); m["deadline_in"] == nil {
+		t.Fatalf("expected deadline_in when ctx has a deadline: %v", m)
+	}
+
+	if m := parseJSONLine(t, string(lines[1])); m["deadline_in"] != nil {
+		t.Fatalf("did not expect deadline_in without a deadline: %v", m)
+	}
+}
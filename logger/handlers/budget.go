@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// defaultReportInterval is used when BudgetOptions.ReportInterval is zero.
+const defaultReportInterval = 10 * time.Second
+
+// ShedMessage is the message of the record reporting records dropped by a Budget.
+const ShedMessage = "log records shed"
+
+// BudgetOptions configures a Budget. Each rate is a token bucket holding up to one second
+// of budget (or Burst records / BurstBytes bytes); a zero rate disables that limit.
+//
+// ErrorReserve is the share of each bucket, between 0 and 1, that only records at Error or
+// above may use, so errors still get through while lower levels are being shed. A bucket
+// holds at least one record (or byte), and the reserve always leaves one record (or byte)
+// of a full bucket to lower levels, so a small burst never shuts them out entirely.
+type BudgetOptions struct {
+	RecordsPerSecond float64
+	BytesPerSecond   float64
+	Burst            float64
+	BurstBytes       float64
+	ErrorReserve     float64
+	ReportInterval   time.Duration
+}
+
+// Budget is a hard cap on the records and bytes per second written by a logger. Records
+// are admitted by the handler returned from Handler; bytes are counted by the writer
+// returned from Writer after each write, so an oversized record is paid for by the records
+// that follow it. Dropped records are counted and reported every ReportInterval by a
+// ShedMessage record at Warn level with shed, shed_total and interval; the report is
+// written with the first record admitted after the interval is over, or when the handler
+// is flushed (see Flusher). It goes through the handler passed to Handler, without the
+// attributes added later with WithAttrs or WithGroup, since it covers all of them.
+//
+// A Budget is safe for concurrent use. A nil or zero Budget admits everything.
+type Budget struct {
+	opts BudgetOptions
+
+	mu       sync.Mutex
+	records  bucket
+	bytes    bucket
+	shed     uint64 // dropped since the last report
+	total    uint64
+	reported time.Time
+}
+
+// bucket is a token bucket; tokens may go negative when bytes are debited after a write.
+type bucket struct {
+	rate    float64
+	burst   float64
+	reserve float64 // tokens kept for records at Error or above
+	tokens  float64
+	last    time.Time
+}
+
+// NewBudget returns a Budget with full buckets.
+func NewBudget(opts BudgetOptions) *Budget {
+	if opts.ReportInterval <= 0 {
+		opts.ReportInterval = defaultReportInterval
+	}
+
+	opts.ErrorReserve = min(max(opts.ErrorReserve, 0), 1)
+
+	now := time.Now()
+	b := &Budget{opts: opts, reported: now}
+	b.records = newBucket(opts.RecordsPerSecond, opts.Burst, opts.ErrorReserve, now)
+	b.bytes = newBucket(opts.BytesPerSecond, opts.BurstBytes, opts.ErrorReserve, now)
+
+	return b
+}
+
+func newBucket(rate, burst, reserve float64, now time.Time) bucket {
+	if burst <= 0 {
+		burst = rate
+	}
+
+	burst = max(burst, 1)
+	reserve = min(reserve*burst, burst-1)
+
+	return bucket{rate: rate, burst: burst, reserve: reserve, tokens: burst, last: now}
+}
+
+// refill adds the tokens earned since the last call.
+func (k *bucket) refill(now time.Time) {
+	k.tokens = min(k.burst, k.tokens+k.rate*now.Sub(k.last).Seconds())
+	k.last = now
+}
+
+// enabled reports whether the budget limits anything.
+func (b *Budget) enabled() bool {
+	return b != nil && (b.records.rate > 0 || b.bytes.rate > 0)
+}
+
+// Dropped returns the number of records dropped so far.
+func (b *Budget) Dropped() uint64 {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.total
+}
+
+// Handler wraps next so that records are dropped once the budget is spent.
+func (b *Budget) Handler(next slog.Handler) slog.Handler {
+	if !b.enabled() {
+		return next
+	}
+
+	return &budgetHandler{next: next, b: b, report: next}
+}
+
+// Writer wraps w so that written bytes are debited from the byte budget.
+func (b *Budget) Writer(w io.Writer) io.Writer {
+	if !b.enabled() || b.bytes.rate <= 0 {
+		return w
+	}
+
+	return &budgetWriter{w: w, b: b}
+}
+
+// admit takes a record token for level and returns a shed report when one is due.
+func (b *Budget) admit(level slog.Level, now time.Time) (bool, *slog.Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var recordsReserve, bytesReserve float64
+	if level < slog.LevelError {
+		recordsReserve, bytesReserve = b.records.reserve, b.bytes.reserve
+	}
+
+	ok := true
+
+	if b.records.rate > 0 {
+		b.records.refill(now)
+		ok = b.records.tokens >= 1+recordsReserve
+	}
+
+	if ok && b.bytes.rate > 0 {
+		b.bytes.refill(now)
+		ok = b.bytes.tokens > bytesReserve
+	}
+
+	if !ok {
+		b.shed++
+		b.total++
+
+		return false, nil
+	}
+
+	if b.records.rate > 0 {
+		b.records.tokens--
+	}
+
+	if now.Sub(b.reported) < b.opts.ReportInterval {
+		return true, nil
+	}
+
+	return true, b.report(now)
+}
+
+// report returns the shed report and starts a new interval, or nil when nothing was shed;
+// the caller holds mu.
+func (b *Budget) report(now time.Time) *slog.Record {
+	if b.shed == 0 {
+		return nil
+	}
+
+	r := slog.NewRecord(now, slog.LevelWarn, ShedMessage, 0)
+	r.AddAttrs(
+		slog.Uint64("shed", b.shed),
+		slog.Uint64("shed_total", b.total),
+		slog.Duration("interval", now.Sub(b.reported)),
+	)
+
+	b.shed, b.reported = 0, now
+
+	return &r
+}
+
+// debit charges n written bytes to the byte budget.
+func (b *Budget) debit(n int) {
+	b.mu.Lock()
+	b.bytes.refill(time.Now())
+	b.bytes.tokens -= float64(n)
+	b.mu.Unlock()
+}
+
+type budgetHandler struct {
+	next   slog.Handler
+	b      *Budget
+	report slog.Handler // handler passed to Handler, writes the shed reports
+}
+
+func (h *budgetHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *budgetHandler) Handle(ctx context.Context, r slog.Record) error {
+	ok, report := h.b.admit(r.Level, time.Now())
+	if !ok {
+		return nil
+	}
+
+	if report != nil {
+		_ = h.report.Handle(ctx, *report)
+	}
+
+	return h.next.Handle(ctx, r)
+}
+
+// Flush writes the pending shed report, if any, then flushes next. The report is written
+// even when the budget is spent.
+func (h *budgetHandler) Flush(ctx context.Context) error {
+	h.b.mu.Lock()
+	report := h.b.report(time.Now())
+	h.b.mu.Unlock()
+
+	if report != nil {
+		_ = h.report.Handle(ctx, *report)
+	}
+
+	return Flush(ctx, h.next)
+}
+
+func (h *budgetHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &budgetHandler{next: h.next.WithAttrs(attrs), b: h.b, report: h.report}
+}
+
+func (h *budgetHandler) WithGroup(name string) slog.Handler {
+	return &budgetHandler{next: h.next.WithGroup(name), b: h.b, report: h.report}
+}
+
+type budgetWriter struct {
+	w io.Writer
+	b *Budget
+}
+
+func (w *budgetWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.b.debit(n)
+
+	return n, err
+}
@@ -0,0 +1,157 @@
+package handlers_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-logger/logger/handlers"
+)
+
+// TestBudgetCapsRecordsAndReservesErrors ensures low levels are shed first and errors keep their share.
+func TestBudgetCapsRecordsAndReservesErrors(t *testing.T) {
+	var buf bytes.Buffer
+
+	b := handlers.NewBudget(handlers.BudgetOptions{RecordsPerSecond: 10, ErrorReserve: 0.5})
+	l := slog.New(b.Handler(handlers.JSON(&buf, slog.HandlerOptions{})))
+
+	for range 20 {
+		l.Info("chatty")
+	}
+
+	for range 10 {
+		l.Error("failure")
+	}
+
+	out := buf.String()
+	if got := strings.Count(out, `"msg":"chatty"`); got != 5 {
+		t.Fatalf("expected info to stop at the error reserve, got %d", got)
+	}
+
+	if got := strings.Count(out, `"msg":"failure"`); got != 5 {
+		t.Fatalf("expected errors to use the reserve, got %d", got)
+	}
+
+	if b.Dropped() != 20 {
+		t.Fatalf("expected 20 dropped records, got %d", b.Dropped())
+	}
+}
+
+// TestBudgetSmallBurstAdmitsLowLevels ensures a reserve larger than the burst allows still
+// leaves one record to levels below Error.
+func TestBudgetSmallBurstAdmitsLowLevels(t *testing.T) {
+	for _, opts := range []handlers.BudgetOptions{
+		{RecordsPerSecond: 2, ErrorReserve: 0.6},
+		{RecordsPerSecond: 0.5, ErrorReserve: 0.2},
+		{RecordsPerSecond: 1, ErrorReserve: 1},
+	} {
+		var buf bytes.Buffer
+
+		b := handlers.NewBudget(opts)
+		l := slog.New(b.Handler(handlers.JSON(&buf, slog.HandlerOptions{})))
+
+		l.Info("first")
+		l.Info("second")
+
+		if got := strings.Count(buf.String(), `"msg":"first"`); got != 1 {
+			t.Fatalf("%+v: expected the first info record to pass, got %s", opts, buf.String())
+		}
+
+		if strings.Contains(buf.String(), `"msg":"second"`) {
+			t.Fatalf("%+v: expected the second info record to be shed, got %s", opts, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+
+	b := handlers.NewBudget(handlers.BudgetOptions{RecordsPerSecond: 2, ErrorReserve: 0.6})
+	l := slog.New(b.Handler(handlers.JSON(&buf, slog.HandlerOptions{})))
+
+	l.Info("info")
+	l.Info("shed")
+	l.Error("failure")
+
+	if strings.Contains(buf.String(), `"msg":"shed"`) || !strings.Contains(buf.String(), `"msg":"failure"`) {
+		t.Fatalf("expected the reserved token to go to the error record: %s", buf.String())
+	}
+}
+
+// TestBudgetCapsBytes ensures written bytes are charged through the writer.
+func TestBudgetCapsBytes(t *testing.T) {
+	var buf bytes.Buffer
+
+	b := handlers.NewBudget(handlers.BudgetOptions{BytesPerSecond: 200})
+	l := slog.New(b.Handler(handlers.JSON(b.Writer(&buf), slog.HandlerOptions{})))
+
+	for range 50 {
+		l.Info("payload", "data", strings.Repeat("x", 50))
+	}
+
+	if n := strings.Count(buf.String(), "\n"); n == 0 || n > 3 {
+		t.Fatalf("expected the byte budget to admit only a few records, got %d", n)
+	}
+
+	if b.Dropped() == 0 {
+		t.Fatal("expected dropped records")
+	}
+}
+
+// TestBudgetReportsShedRecords ensures a report follows once the interval is over.
+func TestBudgetReportsShedRecords(t *testing.T) {
+	var buf bytes.Buffer
+
+	b := handlers.NewBudget(handlers.BudgetOptions{RecordsPerSecond: 100, Burst: 1, ReportInterval: 20 * time.Millisecond})
+	l := slog.New(b.Handler(handlers.JSON(&buf, slog.HandlerOptions{})))
+
+	for range 5 {
+		l.Info("burst")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	l.Info("later")
+
+	out := buf.String()
+	if !strings.Contains(out, `"msg":"`+handlers.ShedMessage+`"`) || !strings.Contains(out, `"shed":4,"shed_total":4`) {
+		t.Fatalf("expected a shed report: %s", out)
+	}
+}
+
+// TestBudgetFlushWritesShedReport ensures Flush reports shed records before the interval is over.
+func TestBudgetFlushWritesShedReport(t *testing.T) {
+	var buf bytes.Buffer
+
+	b := handlers.NewBudget(handlers.BudgetOptions{RecordsPerSecond: 1, ReportInterval: time.Hour})
+	l := slog.New(b.Handler(handlers.JSON(&buf, slog.HandlerOptions{})))
+
+	for range 4 {
+		l.Info("burst")
+	}
+
+	if err := handlers.Flush(t.Context(), l.Handler()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `"shed":3,"shed_total":3`) {
+		t.Fatalf("expected a shed report on flush: %s", buf.String())
+	}
+
+	buf.Reset()
+
+	if err := handlers.Flush(t.Context(), l.Handler()); err != nil || buf.Len() != 0 {
+		t.Fatalf("expected nothing left to flush: %v %s", err, buf.String())
+	}
+}
+
+// TestBudgetZeroIsPassthrough ensures an unconfigured budget changes nothing.
+func TestBudgetZeroIsPassthrough(t *testing.T) {
+	var buf bytes.Buffer
+
+	base := handlers.JSON(&buf, slog.HandlerOptions{})
+	b := handlers.NewBudget(handlers.BudgetOptions{})
+
+	if b.Handler(base) != base || b.Writer(&buf) != &buf {
+		t.Fatal("expected a zero budget to return its arguments unchanged")
+	}
+}
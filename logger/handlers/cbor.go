@@ -0,0 +1,467 @@
+package handlers
+
+import (
+	"context"
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// CBOR major types and simple values (RFC 8949).
+const (
+	cborUint    byte = 0 << 5
+	cborNegInt  byte = 1 << 5
+	cborBytes   byte = 2 << 5
+	cborText    byte = 3 << 5
+	cborArray   byte = 4 << 5
+	cborMap     byte = 5 << 5
+	cborTag     byte = 6 << 5
+	cborSimple  byte = 7 << 5
+	cborFalse   byte = cborSimple | 20
+	cborTrue    byte = cborSimple | 21
+	cborNull    byte = cborSimple | 22
+	cborFloat64 byte = cborSimple | 27
+	cborMapOpen byte = cborMap | 31 // indefinite-length map
+	cborBreak   byte = 0xff
+
+	cborTagEpoch    = 1    // tag 1: epoch-based date/time in whole seconds
+	cborTagExtended = 1001 // tag 1001: extended time (RFC 9581), {1: seconds, -9: nanoseconds}
+)
+
+// cborLengthPrefix is the size of the big-endian length written before every record.
+const cborLengthPrefix = 4
+
+// cborHandler encodes records as length-prefixed CBOR maps. Groups are nested maps, so a
+// decoded record has the same shape as the output of handlers.JSON.
+type cborHandler struct {
+	opts      slog.HandlerOptions
+	w         io.Writer
+	mu        *sync.Mutex
+	groups    []string // every open group, for ReplaceAttr
+	unopened  []string // groups not yet written to preformat (empty groups are omitted)
+	opened    int      // groups written to preformat and left open
+	preformat []byte   // encoded persistent key/value pairs
+}
+
+// CBOR returns a slog.Handler writing each record as a CBOR (RFC 8949) map preceded by
+// its length as a 4-byte big-endian integer. It is a compact alternative to handlers.JSON
+// for high-volume services; use NewCBORDecoder or cmd/scg-cbor2json to read it back.
+func CBOR(w io.Writer, opts slog.HandlerOptions) slog.Handler {
+	return &cborHandler{opts: opts, w: w, mu: &sync.Mutex{}}
+}
+
+func (h *cborHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+
+	return level >= minLevel
+}
+
+func (h *cborHandler) Handle(_ context.Context, r slog.Record) error {
+	bp, _ := bufPool.Get().(*[]byte)
+	buf := append((*bp)[:0], 0, 0, 0, 0, cborMapOpen)
+
+	if !r.Time.IsZero() {
+		buf = h.appendBuiltin(buf, slog.TimeKey, slog.TimeValue(r.Time.Round(0)))
+	}
+
+	buf = h.appendBuiltin(buf, slog.LevelKey, slog.AnyValue(r.Level))
+	buf = h.appendBuiltin(buf, slog.MessageKey, slog.StringValue(r.Message))
+
+	if h.opts.AddSource && r.PC != 0 {
+		buf = h.appendBuiltin(buf, slog.SourceKey, slog.AnyValue(recordSource(r.PC)))
+	}
+
+	buf = append(buf, h.preformat...)
+	opened := h.opened
+
+	if r.NumAttrs() > 0 {
+		// Open pending groups optimistically and roll back if no attribute is written.
+		mark := len(buf)
+
+		for _, g := range h.unopened {
+			buf = appendCBORText(buf, g)
+			buf = append(buf, cborMapOpen)
+		}
+
+		withGroups := len(buf)
+
+		r.Attrs(func(a slog.Attr) bool {
+			buf = h.appendAttr(buf, h.groups, a)
+			return true
+		})
+
+		if len(buf) == withGroups {
+			buf = buf[:mark]
+		} else {
+			opened += len(h.unopened)
+		}
+	}
+
+	for range opened + 1 {
+		buf = append(buf, cborBreak)
+	}
+
+	//nolint:gosec // records are far below 4 GiB; the prefix is a framing length.
+	binary.BigEndian.PutUint32(buf, uint32(len(buf)-cborLengthPrefix))
+
+	h.mu.Lock()
+	_, err := h.w.Write(buf)
+	h.mu.Unlock()
+
+	*bp = buf
+	bufPool.Put(bp)
+
+	return err
+}
+
+func (h *cborHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var enc []byte
+	for _, a := range attrs {
+		enc = h.appendAttr(enc, h.groups, a)
+	}
+
+	if len(enc) == 0 {
+		return h
+	}
+
+	h2 := *h
+	h2.preformat = slices.Clip(h.preformat)
+
+	for _, g := range h.unopened {
+		h2.preformat = appendCBORText(h2.preformat, g)
+		h2.preformat = append(h2.preformat, cborMapOpen)
+	}
+
+	h2.preformat = append(h2.preformat, enc...)
+	h2.opened += len(h.unopened)
+	h2.unopened = nil
+
+	return &h2
+}
+
+func (h *cborHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.groups = append(slices.Clip(h.groups), name)
+	h2.unopened = append(slices.Clip(h.unopened), name)
+
+	return &h2
+}
+
+// appendBuiltin applies ReplaceAttr to a built-in attribute and encodes it unless removed.
+func (h *cborHandler) appendBuiltin(buf []byte, key string, v slog.Value) []byte {
+	a := slog.Attr{Key: key, Value: v}
+	if h.opts.ReplaceAttr != nil {
+		a = h.opts.ReplaceAttr(nil, a)
+		a.Value = a.Value.Resolve()
+	}
+
+	if a.Key == "" {
+		return buf
+	}
+
+	// Schemas turn source into a group of their own keys; encode it as a nested map.
+	if a.Value.Kind() == slog.KindGroup {
+		return h.appendAttr(buf, nil, a)
+	}
+
+	buf = appendCBORText(buf, a.Key)
+
+	return appendCBORValue(buf, a.Value)
+}
+
+// appendAttr encodes a as a key/value pair following slog's rules for empty attributes
+// and groups; groups with an empty key are inlined.
+func (h *cborHandler) appendAttr(buf []byte, groups []string, a slog.Attr) []byte {
+	a.Value = a.Value.Resolve()
+
+	if h.opts.ReplaceAttr != nil && a.Value.Kind() != slog.KindGroup {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+
+	if a.Equal(slog.Attr{}) {
+		return buf
+	}
+
+	if a.Value.Kind() != slog.KindGroup {
+		buf = appendCBORText(buf, a.Key)
+
+		return appendCBORValue(buf, a.Value)
+	}
+
+	if a.Key != "" {
+		groups = append(slices.Clip(groups), a.Key)
+	}
+
+	var inner []byte
+	for _, ga := range a.Value.Group() {
+		inner = h.appendAttr(inner, groups, ga)
+	}
+
+	if len(inner) == 0 {
+		return buf
+	}
+
+	if a.Key == "" {
+		return append(buf, inner...)
+	}
+
+	buf = appendCBORText(buf, a.Key)
+	buf = append(buf, cborMapOpen)
+	buf = append(buf, inner...)
+
+	return append(buf, cborBreak)
+}
+
+// appendCBORHead writes a major type with its argument in the shortest form.
+func appendCBORHead(buf []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(buf, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, major|27), n)
+	}
+}
+
+func appendCBORText(buf []byte, s string) []byte {
+	buf = appendCBORHead(buf, cborText, uint64(len(s)))
+
+	return append(buf, s...)
+}
+
+func appendCBORInt(buf []byte, n int64) []byte {
+	if n < 0 {
+		//nolint:gosec // -1-n is non-negative for negative n.
+		return appendCBORHead(buf, cborNegInt, uint64(-1-n))
+	}
+
+	return appendCBORHead(buf, cborUint, uint64(n))
+}
+
+func appendCBORFloat(buf []byte, f float64) []byte {
+	return binary.BigEndian.AppendUint64(append(buf, cborFloat64), math.Float64bits(f))
+}
+
+// appendCBORTime writes t as tag 1 with integer seconds, or as a tag 1001 extended time
+// with exact nanoseconds when it is sub-second.
+func appendCBORTime(buf []byte, t time.Time) []byte {
+	if t.Nanosecond() == 0 {
+		buf = appendCBORHead(buf, cborTag, cborTagEpoch)
+
+		return appendCBORInt(buf, t.Unix())
+	}
+
+	buf = appendCBORHead(buf, cborTag, cborTagExtended)
+	buf = append(buf, cborMap|2)
+	buf = appendCBORInt(buf, 1)
+	buf = appendCBORInt(buf, t.Unix())
+	buf = appendCBORInt(buf, -9)
+
+	return appendCBORInt(buf, int64(t.Nanosecond()))
+}
+
+// appendCBORValue encodes a resolved, non-group slog.Value.
+func appendCBORValue(buf []byte, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindString:
+		return appendCBORText(buf, v.String())
+	case slog.KindInt64:
+		return appendCBORInt(buf, v.Int64())
+	case slog.KindUint64:
+		return appendCBORHead(buf, cborUint, v.Uint64())
+	case slog.KindFloat64:
+		return appendCBORFloat(buf, v.Float64())
+	case slog.KindBool:
+		if v.Bool() {
+			return append(buf, cborTrue)
+		}
+
+		return append(buf, cborFalse)
+	case slog.KindDuration:
+		return appendCBORInt(buf, int64(v.Duration()))
+	case slog.KindTime:
+		return appendCBORTime(buf, v.Time())
+	case slog.KindAny, slog.KindLogValuer, slog.KindGroup:
+		return appendCBORAny(buf, v.Any())
+	default:
+		return appendCBORText(buf, v.String())
+	}
+}
+
+// appendCBORAny encodes values of arbitrary type, mirroring what handlers.JSON would emit.
+func appendCBORAny(buf []byte, x any) []byte {
+	switch t := x.(type) {
+	case nil:
+		return append(buf, cborNull)
+	case *slog.Source:
+		if t == nil {
+			return append(buf, cborNull)
+		}
+
+		buf = append(buf, cborMap|3)
+		buf = appendCBORText(buf, "function")
+		buf = appendCBORText(buf, t.Function)
+		buf = appendCBORText(buf, "file")
+		buf = appendCBORText(buf, t.File)
+		buf = appendCBORText(buf, "line")
+
+		return appendCBORInt(buf, int64(t.Line))
+	case slog.Level:
+		return appendCBORText(buf, t.String())
+	case error:
+		return appendCBORText(buf, t.Error())
+	case []byte:
+		buf = appendCBORHead(buf, cborBytes, uint64(len(t)))
+		return append(buf, t...)
+	case string:
+		return appendCBORText(buf, t)
+	case bool:
+		return appendCBORValue(buf, slog.BoolValue(t))
+	case time.Time:
+		return appendCBORTime(buf, t)
+	case json.Marshaler:
+		return appendCBORJSON(buf, x)
+	case encoding.TextMarshaler:
+		b, err := t.MarshalText()
+		if err != nil {
+			return appendCBORText(buf, "!ERROR:"+err.Error())
+		}
+
+		return appendCBORText(buf, string(b))
+	}
+
+	return appendCBORReflect(buf, reflect.ValueOf(x))
+}
+
+// appendCBORReflect encodes numbers, slices and string-keyed maps without going through JSON.
+func appendCBORReflect(buf []byte, rv reflect.Value) []byte {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendCBORInt(buf, rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendCBORHead(buf, cborUint, rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return appendCBORFloat(buf, rv.Float())
+	case reflect.String:
+		return appendCBORText(buf, rv.String())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return append(buf, cborNull)
+		}
+
+		buf = appendCBORHead(buf, cborArray, uint64(rv.Len()))
+		for i := range rv.Len() {
+			buf = appendCBORAny(buf, rv.Index(i).Interface())
+		}
+
+		return buf
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return appendCBORJSON(buf, rv.Interface())
+		}
+
+		if rv.IsNil() {
+			return append(buf, cborNull)
+		}
+
+		keys := rv.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			switch {
+			case a.String() < b.String():
+				return -1
+			case a.String() > b.String():
+				return 1
+			default:
+				return 0
+			}
+		})
+
+		buf = appendCBORHead(buf, cborMap, uint64(len(keys)))
+		for _, k := range keys {
+			buf = appendCBORText(buf, k.String())
+			buf = appendCBORAny(buf, rv.MapIndex(k).Interface())
+		}
+
+		return buf
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return append(buf, cborNull)
+		}
+
+		return appendCBORAny(buf, rv.Elem().Interface())
+	default:
+		return appendCBORJSON(buf, rv.Interface())
+	}
+}
+
+// appendCBORJSON encodes x through its JSON form, which is what handlers.JSON emits for
+// structs and custom marshalers.
+func appendCBORJSON(buf []byte, x any) []byte {
+	b, err := json.Marshal(x)
+	if err != nil {
+		return appendCBORText(buf, "!ERROR:"+err.Error())
+	}
+
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return appendCBORText(buf, "!ERROR:"+err.Error())
+	}
+
+	return appendCBORJSONValue(buf, v)
+}
+
+// appendCBORJSONValue encodes a value produced by json.Unmarshal into any.
+func appendCBORJSONValue(buf []byte, v any) []byte {
+	switch t := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+
+		slices.Sort(keys)
+
+		buf = appendCBORHead(buf, cborMap, uint64(len(keys)))
+		for _, k := range keys {
+			buf = appendCBORText(buf, k)
+			buf = appendCBORJSONValue(buf, t[k])
+		}
+
+		return buf
+	case []any:
+		buf = appendCBORHead(buf, cborArray, uint64(len(t)))
+		for _, e := range t {
+			buf = appendCBORJSONValue(buf, e)
+		}
+
+		return buf
+	case float64:
+		if t == math.Trunc(t) && math.Abs(t) < 1<<53 {
+			return appendCBORInt(buf, int64(t))
+		}
+
+		return appendCBORFloat(buf, t)
+	default:
+		return appendCBORAny(buf, t)
+	}
+}
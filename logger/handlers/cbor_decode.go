@@ -0,0 +1,467 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// maxCBORRecord guards the decoder against corrupt length prefixes.
+const maxCBORRecord = 64 << 20
+
+// maxCBORDepth bounds the nesting of arrays, maps and tags so corrupt or hostile input
+// cannot exhaust the stack.
+const maxCBORDepth = 128
+
+// ErrCBORMalformed is returned when the input is not a stream written by handlers.CBOR.
+var ErrCBORMalformed = errors.New("malformed cbor record")
+
+// CBORDecoder reads records written by handlers.CBOR.
+type CBORDecoder struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+// NewCBORDecoder returns a decoder reading length-prefixed CBOR records from r.
+func NewCBORDecoder(r io.Reader) *CBORDecoder {
+	return &CBORDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the next record. Maps decode to map[string]any, integers to int64 or
+// uint64, byte strings to []byte and tagged times to time.Time. It returns io.EOF
+// after the last record.
+func (d *CBORDecoder) Decode() (map[string]any, error) {
+	v, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := plainValue(v).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: record is not a map", ErrCBORMalformed)
+	}
+
+	return m, nil
+}
+
+// DecodeJSON reads the next record and appends it to dst as one JSON line, keeping the
+// encoded key order. It returns io.EOF after the last record.
+func (d *CBORDecoder) DecodeJSON(dst []byte) ([]byte, error) {
+	v, err := d.next()
+	if err != nil {
+		return dst, err
+	}
+
+	if _, ok := v.(cborMapValue); !ok {
+		return dst, fmt.Errorf("%w: record is not a map", ErrCBORMalformed)
+	}
+
+	dst = appendJSONValue(dst, v)
+
+	return append(dst, '\n'), nil
+}
+
+// next reads one length-prefixed record and parses its single top-level item.
+func (d *CBORDecoder) next() (any, error) {
+	var prefix [cborLengthPrefix]byte
+	if _, err := io.ReadFull(d.r, prefix[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: truncated length prefix", ErrCBORMalformed)
+		}
+
+		return nil, err
+	}
+
+	n := binary.BigEndian.Uint32(prefix[:])
+	if n > maxCBORRecord {
+		return nil, fmt.Errorf("%w: record of %d bytes", ErrCBORMalformed, n)
+	}
+
+	if cap(d.buf) < int(n) {
+		d.buf = make([]byte, n)
+	}
+
+	d.buf = d.buf[:n]
+	if _, err := io.ReadFull(d.r, d.buf); err != nil {
+		return nil, fmt.Errorf("%w: truncated record: %w", ErrCBORMalformed, err)
+	}
+
+	p := cborParser{data: d.buf}
+
+	v, err := p.item()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos != len(p.data) {
+		return nil, fmt.Errorf("%w: trailing bytes", ErrCBORMalformed)
+	}
+
+	return v, nil
+}
+
+// cborMapValue keeps map entries in encoded order.
+type cborMapValue []cborEntry
+
+type cborEntry struct {
+	key   string
+	value any
+}
+
+// cborBreakValue marks the end of an indefinite-length item.
+type cborBreakValue struct{}
+
+// cborParser decodes the subset of CBOR produced by handlers.CBOR.
+type cborParser struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (p *cborParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w at offset %d: %s", ErrCBORMalformed, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *cborParser) take(n uint64) ([]byte, error) {
+	if n > uint64(len(p.data)-p.pos) {
+		return nil, p.errorf("need %d bytes", n)
+	}
+
+	b := p.data[p.pos : p.pos+int(n)]
+	p.pos += int(n)
+
+	return b, nil
+}
+
+// head reads an initial byte and its argument; indefinite reports additional info 31.
+func (p *cborParser) head() (major, info byte, arg uint64, err error) {
+	b, err := p.take(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	major, info = b[0]&0xe0, b[0]&0x1f
+
+	const (
+		oneByte   = 24
+		twoBytes  = 25
+		fourBytes = 26
+		eightByte = 27
+	)
+
+	switch {
+	case info < oneByte:
+		return major, info, uint64(info), nil
+	case info == oneByte:
+		b, err = p.take(1)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		return major, info, uint64(b[0]), nil
+	case info == twoBytes:
+		b, err = p.take(2)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		return major, info, uint64(binary.BigEndian.Uint16(b)), nil
+	case info == fourBytes:
+		b, err = p.take(4)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		return major, info, uint64(binary.BigEndian.Uint32(b)), nil
+	case info == eightByte:
+		b, err = p.take(8)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		return major, info, binary.BigEndian.Uint64(b), nil
+	case info == 31:
+		return major, info, 0, nil
+	default:
+		return 0, 0, 0, p.errorf("reserved additional info %d", info)
+	}
+}
+
+//nolint:cyclop // one case per CBOR major type.
+func (p *cborParser) item() (any, error) {
+	major, info, arg, err := p.head()
+	if err != nil {
+		return nil, err
+	}
+
+	if major == cborArray || major == cborMap || major == cborTag {
+		if p.depth++; p.depth > maxCBORDepth {
+			return nil, p.errorf("nesting deeper than %d", maxCBORDepth)
+		}
+		defer func() { p.depth-- }()
+	}
+
+	indefinite := info == 31
+
+	switch major {
+	case cborUint:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+
+		return int64(arg), nil
+	case cborNegInt:
+		if arg > math.MaxInt64 {
+			return nil, p.errorf("negative integer overflow")
+		}
+
+		return -1 - int64(arg), nil
+	case cborBytes, cborText:
+		if indefinite {
+			return nil, p.errorf("indefinite strings are not supported")
+		}
+
+		b, err := p.take(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		if major == cborText {
+			return string(b), nil
+		}
+
+		return append([]byte(nil), b...), nil
+	case cborArray:
+		return p.array(arg, indefinite)
+	case cborMap:
+		return p.mapValue(arg, indefinite)
+	case cborTag:
+		return p.tagged(arg)
+	default:
+		return p.simple(info, arg)
+	}
+}
+
+func (p *cborParser) array(n uint64, indefinite bool) (any, error) {
+	var out []any
+
+	for i := uint64(0); indefinite || i < n; i++ {
+		v, err := p.item()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, done := v.(cborBreakValue); done {
+			if !indefinite {
+				return nil, p.errorf("unexpected break")
+			}
+
+			break
+		}
+
+		out = append(out, v)
+	}
+
+	return out, nil
+}
+
+func (p *cborParser) mapValue(n uint64, indefinite bool) (any, error) {
+	var out cborMapValue
+
+	for i := uint64(0); indefinite || i < n; i++ {
+		k, err := p.item()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, done := k.(cborBreakValue); done {
+			if !indefinite {
+				return nil, p.errorf("unexpected break")
+			}
+
+			break
+		}
+
+		key, ok := k.(string)
+		if !ok {
+			return nil, p.errorf("map key of type %T", k)
+		}
+
+		v, err := p.item()
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, cborEntry{key: key, value: v})
+	}
+
+	return out, nil
+}
+
+func (p *cborParser) tagged(tag uint64) (any, error) {
+	if tag == cborTagExtended {
+		return p.extendedTime()
+	}
+
+	v, err := p.item()
+	if err != nil {
+		return nil, err
+	}
+
+	if tag != cborTagEpoch {
+		return v, nil
+	}
+
+	switch t := v.(type) {
+	case int64:
+		return time.Unix(t, 0).UTC(), nil
+	case float64:
+		sec, frac := math.Modf(t)
+		return time.Unix(int64(sec), int64(math.Round(frac*float64(time.Second)))).UTC(), nil
+	default:
+		return nil, p.errorf("epoch time of type %T", v)
+	}
+}
+
+// extendedTime decodes the map of a tag 1001 time: key 1 holds seconds and keys -3, -6
+// or -9 the fraction in milli-, micro- or nanoseconds.
+func (p *cborParser) extendedTime() (any, error) {
+	major, _, n, err := p.head()
+	if err != nil {
+		return nil, err
+	}
+
+	if major != cborMap || n > 2 {
+		return nil, p.errorf("extended time must be a small map")
+	}
+
+	var sec, nsec int64
+
+	for range n {
+		k, err := p.item()
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := p.item()
+		if err != nil {
+			return nil, err
+		}
+
+		key, ok1 := k.(int64)
+		val, ok2 := v.(int64)
+
+		if !ok1 || !ok2 {
+			return nil, p.errorf("extended time entries must be integers")
+		}
+
+		switch key {
+		case 1:
+			sec = val
+		case -3:
+			nsec = val * int64(time.Millisecond)
+		case -6:
+			nsec = val * int64(time.Microsecond)
+		case -9:
+			nsec = val
+		default:
+			return nil, p.errorf("unsupported extended time key %d", key)
+		}
+	}
+
+	return time.Unix(sec, nsec).UTC(), nil
+}
+
+func (p *cborParser) simple(info byte, arg uint64) (any, error) {
+	switch info {
+	case cborFalse & 0x1f:
+		return false, nil
+	case cborTrue & 0x1f:
+		return true, nil
+	case cborNull & 0x1f:
+		return nil, nil
+	case cborFloat64 & 0x1f:
+		return math.Float64frombits(arg), nil
+	case 31:
+		return cborBreakValue{}, nil
+	default:
+		return nil, p.errorf("unsupported simple value %d", info)
+	}
+}
+
+// plainValue converts ordered maps to map[string]any recursively.
+func plainValue(v any) any {
+	switch t := v.(type) {
+	case cborMapValue:
+		m := make(map[string]any, len(t))
+		for _, e := range t {
+			m[e.key] = plainValue(e.value)
+		}
+
+		return m
+	case []any:
+		for i := range t {
+			t[i] = plainValue(t[i])
+		}
+
+		return t
+	default:
+		return v
+	}
+}
+
+// appendJSONValue writes a decoded value as JSON, rendering times as RFC 3339 and
+// non-finite floats as strings.
+func appendJSONValue(dst []byte, v any) []byte {
+	switch t := v.(type) {
+	case cborMapValue:
+		dst = append(dst, '{')
+		for i, e := range t {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+
+			dst = appendJSONValue(dst, e.key)
+			dst = append(dst, ':')
+			dst = appendJSONValue(dst, e.value)
+		}
+
+		return append(dst, '}')
+	case []any:
+		dst = append(dst, '[')
+		for i, e := range t {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+
+			dst = appendJSONValue(dst, e)
+		}
+
+		return append(dst, ']')
+	case int64:
+		return strconv.AppendInt(dst, t, 10)
+	case uint64:
+		return strconv.AppendUint(dst, t, 10)
+	case float64:
+		if math.IsNaN(t) || math.IsInf(t, 0) {
+			return strconv.AppendQuote(dst, strconv.FormatFloat(t, 'g', -1, 64))
+		}
+
+		return strconv.AppendFloat(dst, t, 'g', -1, 64)
+	case time.Time:
+		return appendJSONValue(dst, t.Format(time.RFC3339Nano))
+	default:
+		b, err := json.Marshal(t)
+		if err != nil {
+			return strconv.AppendQuote(dst, "!ERROR:"+err.Error())
+		}
+
+		return append(dst, b...)
+	}
+}
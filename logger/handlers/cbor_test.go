@@ -0,0 +1,181 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-logger/logger/handlers"
+)
+
+// TestCBORRoundTrip ensures decoded records match the JSON handler output shape.
+func TestCBORRoundTrip(t *testing.T) {
+	var bin, js bytes.Buffer
+
+	for _, h := range []slog.Handler{
+		handlers.CBOR(&bin, slog.HandlerOptions{}),
+		handlers.JSON(&js, slog.HandlerOptions{}),
+	} {
+		l := slog.New(h).With("service", "svc").WithGroup("req")
+		l.Info("first", "id", 7, "neg", -3, "ok", true, "ratio", 0.5, "err", errors.New("boom"),
+			"tags", []string{"a", "b"}, "empty", slog.GroupValue(), slog.Group("user", "name", "ann"))
+		l.Warn("second", "wait", time.Second)
+	}
+
+	dec := handlers.NewCBORDecoder(&bin)
+
+	for _, want := range bytes.Split(bytes.TrimSpace(js.Bytes()), []byte("\n")) {
+		line, err := dec.DecodeJSON(nil)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+
+		var got, exp map[string]any
+		if err := json.Unmarshal(line, &got); err != nil {
+			t.Fatalf("converted line is not JSON: %v %s", err, line)
+		}
+
+		if err := json.Unmarshal(want, &exp); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+
+		delete(got, "time")
+		delete(exp, "time")
+
+		g, _ := json.Marshal(got)
+		e, _ := json.Marshal(exp)
+
+		if !bytes.Equal(g, e) {
+			t.Fatalf("decoded record differs from JSON handler:\n got %s\nwant %s", g, e)
+		}
+	}
+
+	if _, err := dec.Decode(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF after last record, got %v", err)
+	}
+}
+
+// TestCBORDecodeTypes ensures time and integers decode to Go types.
+func TestCBORDecodeTypes(t *testing.T) {
+	var buf bytes.Buffer
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	slog.New(handlers.CBOR(&buf, slog.HandlerOptions{})).Info("typed", "at", ts, "n", 42, "raw", []byte{1, 2})
+
+	m, err := handlers.NewCBORDecoder(&buf).Decode()
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if at, ok := m["at"].(time.Time); !ok || !at.Equal(ts) {
+		t.Fatalf("expected time value, got %#v", m["at"])
+	}
+
+	if m["n"] != int64(42) || !bytes.Equal(m["raw"].([]byte), []byte{1, 2}) {
+		t.Fatalf("unexpected decoded values: %#v", m)
+	}
+}
+
+// TestCBORDecodeRejectsCorruptInput ensures truncated streams report ErrCBORMalformed.
+func TestCBORDecodeRejectsCorruptInput(t *testing.T) {
+	var buf bytes.Buffer
+
+	slog.New(handlers.CBOR(&buf, slog.HandlerOptions{})).Info("line")
+
+	truncated := buf.Bytes()[:buf.Len()-2]
+	if _, err := handlers.NewCBORDecoder(bytes.NewReader(truncated)).Decode(); !errors.Is(err, handlers.ErrCBORMalformed) {
+		t.Fatalf("expected ErrCBORMalformed, got %v", err)
+	}
+}
+
+// TestCBORSubSecondTimeIsExact ensures nanosecond timestamps survive a round trip.
+func TestCBORSubSecondTimeIsExact(t *testing.T) {
+	var buf bytes.Buffer
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	slog.New(handlers.CBOR(&buf, slog.HandlerOptions{})).Info("typed", "at", ts)
+
+	m, err := handlers.NewCBORDecoder(&buf).Decode()
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if at, ok := m["at"].(time.Time); !ok || !at.Equal(ts) {
+		t.Fatalf("expected %v, got %#v", ts, m["at"])
+	}
+}
+
+// TestCBORSchemaSourceIsNestedMap ensures a built-in replaced by a group is encoded like
+// any other group.
+func TestCBORSchemaSourceIsNestedMap(t *testing.T) {
+	var buf bytes.Buffer
+
+	opts := slog.HandlerOptions{AddSource: true, ReplaceAttr: handlers.SchemaECS().ReplaceAttr(nil)}
+	slog.New(handlers.CBOR(&buf, opts)).Info("line")
+
+	m, err := handlers.NewCBORDecoder(&buf).Decode()
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	origin, ok := m["log.origin"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected log.origin map, got %#v", m["log.origin"])
+	}
+
+	if _, ok := origin["file.line"].(int64); !ok {
+		t.Fatalf("expected integer file.line, got %#v", origin)
+	}
+}
+
+// TestCBORDecodeRejectsDeepNesting ensures nesting is bounded instead of recursing without limit.
+func TestCBORDecodeRejectsDeepNesting(t *testing.T) {
+	const depth = 10000
+
+	rec := bytes.Repeat([]byte{0x81}, depth) // arrays of one element
+	rec = append(rec, 0)
+
+	stream := binary.BigEndian.AppendUint32(nil, uint32(len(rec)))
+	stream = append(stream, rec...)
+
+	if _, err := handlers.NewCBORDecoder(bytes.NewReader(stream)).Decode(); !errors.Is(err, handlers.ErrCBORMalformed) {
+		t.Fatalf("expected ErrCBORMalformed, got %v", err)
+	}
+}
+
+// countingWriter discards output and counts bytes written.
+type countingWriter struct{ n int64 }
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+func benchmarkHandler(b *testing.B, newHandler func(io.Writer, slog.HandlerOptions) slog.Handler) {
+	b.Helper()
+
+	var w countingWriter
+
+	l := slog.New(newHandler(&w, slog.HandlerOptions{})).With("service", "bench-svc")
+	ctx := b.Context()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		l.InfoContext(ctx, "request served",
+			"method", "GET", "path", "/api/v1/orders", "status", 200,
+			"duration", 1500*time.Microsecond, "bytes", 5120, "cached", false)
+	}
+
+	b.ReportMetric(float64(w.n)/float64(b.N), "bytes/record")
+}
+
+// BenchmarkEncodingJSON and BenchmarkEncodingCBOR compare ns/op and bytes per record.
+func BenchmarkEncodingJSON(b *testing.B) { benchmarkHandler(b, handlers.JSON) }
+
+func BenchmarkEncodingCBOR(b *testing.B) { benchmarkHandler(b, handlers.CBOR) }
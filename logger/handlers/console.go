@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ANSI escape sequences used by the console format.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiDim     = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+)
+
+const (
+	consoleTimeFormat = "15:04:05.000"
+	consoleLevelWidth = 5  // len("ERROR")
+	consoleMsgWidth   = 40 // messages are padded so fields start in the same column
+	consoleIndent     = "    "
+)
+
+// Console returns a slog.Handler for local development: dim timestamps, colored and padded
+// level labels, a highlighted message, aligned key=value fields and multi-line values
+// (errors, stack traces) rendered as indented blocks below the line.
+//
+// Colors are enabled only when w is a terminal and the NO_COLOR environment variable is
+// unset; use ConsoleColored to force the choice.
+func Console(w io.Writer, opts slog.HandlerOptions) slog.Handler {
+	return ConsoleColored(w, opts, ColorEnabled(w))
+}
+
+// ConsoleColored is Console with colors explicitly enabled or disabled.
+func ConsoleColored(w io.Writer, opts slog.HandlerOptions, colored bool) slog.Handler {
+	return newFlatHandler(w, opts, consoleEncoder{colored: colored})
+}
+
+// ColorEnabled reports whether ANSI colors should be written to w: w must be a terminal,
+// NO_COLOR (https://no-color.org) must be unset and TERM must not be "dumb".
+func ColorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// consoleEncoder renders records for humans.
+type consoleEncoder struct {
+	colored bool
+}
+
+func (e consoleEncoder) encode(buf []byte, rec *flatRecord) []byte {
+	if rec.time.key != "" {
+		ts := valueString(rec.time.value)
+		if rec.time.value.Kind() == slog.KindTime {
+			ts = rec.time.value.Time().Format(consoleTimeFormat)
+		}
+
+		buf = e.paint(buf, ansiDim, ts)
+		buf = append(buf, ' ')
+	}
+
+	if rec.level.key != "" {
+		label := valueString(rec.level.value)
+		buf = e.paint(buf, levelColor(rec.level.value), pad(label, consoleLevelWidth))
+		buf = append(buf, ' ')
+	}
+
+	if rec.source.key != "" {
+		buf = e.paint(buf, ansiDim, valueString(rec.source.value))
+		buf = append(buf, ' ')
+	}
+
+	var inline, blocks []field
+
+	for _, f := range rec.fields {
+		if strings.Contains(valueString(f.value), "\n") {
+			blocks = append(blocks, f)
+		} else {
+			inline = append(inline, f)
+		}
+	}
+
+	if rec.msg.key != "" {
+		msg := valueString(rec.msg.value)
+		if len(inline) > 0 {
+			msg = pad(msg, consoleMsgWidth)
+		}
+
+		buf = e.paint(buf, ansiBold, msg)
+	}
+
+	for _, f := range inline {
+		buf = append(buf, ' ')
+		buf = e.appendKey(buf, f.key)
+		buf = appendLogfmtString(buf, valueString(f.value))
+	}
+
+	if len(buf) > 0 && buf[len(buf)-1] == ' ' {
+		buf = buf[:len(buf)-1]
+	}
+
+	buf = append(buf, '\n')
+
+	for _, f := range blocks {
+		buf = append(buf, consoleIndent...)
+		buf = e.paint(buf, keyColor(f.key), f.key)
+		buf = append(buf, ":\n"...)
+
+		for line := range strings.Lines(valueString(f.value)) {
+			buf = append(buf, consoleIndent+consoleIndent...)
+			buf = e.paint(buf, blockColor(f.key), strings.TrimRight(line, "\n"))
+			buf = append(buf, '\n')
+		}
+	}
+
+	return buf
+}
+
+// appendKey writes "key=" with the key colored (red for errors).
+func (e consoleEncoder) appendKey(buf []byte, key string) []byte {
+	buf = e.paint(buf, keyColor(key), key)
+
+	return append(buf, '=')
+}
+
+// paint writes s wrapped in the given color when colors are enabled.
+func (e consoleEncoder) paint(buf []byte, color, s string) []byte {
+	if !e.colored || color == "" {
+		return append(buf, s...)
+	}
+
+	buf = append(buf, color...)
+	buf = append(buf, s...)
+
+	return append(buf, ansiReset...)
+}
+
+// levelColor picks a color from the level value (slog.Level or its text form).
+func levelColor(v slog.Value) string {
+	lvl, ok := v.Any().(slog.Level)
+	if !ok {
+		if err := lvl.UnmarshalText([]byte(valueString(v))); err != nil {
+			return ""
+		}
+	}
+
+	switch {
+	case lvl >= slog.LevelError:
+		return ansiRed
+	case lvl >= slog.LevelWarn:
+		return ansiYellow
+	case lvl >= slog.LevelInfo:
+		return ansiGreen
+	default:
+		return ansiMagenta
+	}
+}
+
+// keyColor highlights error keys in red and the others in cyan.
+func keyColor(key string) string {
+	if isErrorKey(key) {
+		return ansiRed
+	}
+
+	return ansiCyan
+}
+
+// blockColor colors multi-line values: errors and stack traces in red.
+func blockColor(key string) string {
+	if isErrorKey(key) || strings.Contains(key, "stack") {
+		return ansiRed
+	}
+
+	return ""
+}
+
+func isErrorKey(key string) bool {
+	return key == KeyError || key == "err" || strings.HasSuffix(key, ".error")
+}
+
+// pad right-pads s with spaces to width terminal columns.
+func pad(s string, width int) string {
+	n := displayWidth(s)
+	if n >= width {
+		return s
+	}
+
+	return s + strings.Repeat(" ", width-n)
+}
+
+// displayWidth approximates the terminal columns taken by s: combining marks and format
+// characters take none, East Asian wide characters and emoji take two.
+func displayWidth(s string) int {
+	n := 0
+
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		case isWide(r):
+			n += 2
+		default:
+			n++
+		}
+	}
+
+	return n
+}
+
+func isWide(r rune) bool {
+	switch {
+	case r >= 0xFF61 && r <= 0xFFDC: // halfwidth katakana and hangul
+		return false
+	case unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana):
+		return true
+	default:
+		return (r >= 0xFF01 && r <= 0xFF60) || // fullwidth forms
+			(r >= 0xFFE0 && r <= 0xFFE6) ||
+			(r >= 0x1F300 && r <= 0x1FAFF) // pictographs and emoji
+	}
+}
@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/next-trace/scg-logger/logger/handlers"
+)
+
+// TestConsolePlainLayout ensures the uncolored layout pads level and message and aligns fields.
+func TestConsolePlainLayout(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.ConsoleColored(&buf, slog.HandlerOptions{ReplaceAttr: dropTime}, false)
+	slog.New(h).Info("short", "user", "ann")
+	slog.New(h).Info("a much longer message", "user", "bob")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected two lines: %q", buf.String())
+	}
+
+	if !strings.HasPrefix(lines[0], "INFO  short ") {
+		t.Fatalf("expected padded level label: %q", lines[0])
+	}
+
+	if strings.Index(lines[0], "user=") != strings.Index(lines[1], "user=") {
+		t.Fatalf("expected aligned fields:\n%s", buf.String())
+	}
+
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Fatalf("did not expect ANSI escapes without color: %q", buf.String())
+	}
+}
+
+// TestConsolePadsByDisplayWidth ensures fields stay aligned after wide and accented messages.
+func TestConsolePadsByDisplayWidth(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.ConsoleColored(&buf, slog.HandlerOptions{ReplaceAttr: dropTime}, false)
+
+	for msg, width := range map[string]int{"short": 5, "café": 4, "cafe\u0301": 4, "日本語": 6, "🚀 go": 5} {
+		buf.Reset()
+		slog.New(h).Info(msg, "user", "ann")
+
+		if want := msg + strings.Repeat(" ", 40-width+1) + "user=ann"; !strings.Contains(buf.String(), want) {
+			t.Fatalf("expected %q padded to 40 columns: %q", msg, buf.String())
+		}
+	}
+}
+
+// TestConsoleColoredMultilineError ensures colors are written and multi-line errors become blocks.
+func TestConsoleColoredMultilineError(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.ConsoleColored(&buf, slog.HandlerOptions{}, true)
+	slog.New(h).Error("failed", "error", errors.New("boom\ngoroutine 1 [running]"), "attempt", 2)
+
+	out := buf.String()
+	if !strings.Contains(out, "\x1b[31mERROR\x1b[0m") {
+		t.Fatalf("expected red level label: %q", out)
+	}
+
+	if !strings.Contains(out, "attempt\x1b[0m=2\n") {
+		t.Fatalf("expected single-line fields inline: %q", out)
+	}
+
+	if !strings.Contains(out, "\n        \x1b[31mgoroutine 1 [running]\x1b[0m\n") {
+		t.Fatalf("expected indented multi-line error block: %q", out)
+	}
+}
+
+// TestColorEnabledHonoursNoColor ensures NO_COLOR and non-terminal writers disable colors.
+func TestColorEnabledHonoursNoColor(t *testing.T) {
+	if handlers.ColorEnabled(&bytes.Buffer{}) {
+		t.Fatal("expected no color for non-terminal writer")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+
+	if handlers.ColorEnabled(nil) {
+		t.Fatal("expected no color when NO_COLOR is set")
+	}
+}
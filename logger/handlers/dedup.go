@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RepeatedMessage is the message of the record reporting suppressed duplicates.
+const RepeatedMessage = "previous message repeated"
+
+// dedupState is shared by a dedup handler and every handler derived from it.
+type dedupState struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	last    []byte       // fingerprint of the last written record
+	since   time.Time    // when the last record was written
+	handler slog.Handler // handler that wrote it, used for the summary
+	level   slog.Level
+	msg     string
+	count   int
+	first   time.Time // times of the first and last suppressed duplicates
+	final   time.Time
+}
+
+type dedupHandler struct {
+	next   slog.Handler
+	prefix []byte // fingerprint of persistent attributes and groups
+	s      *dedupState
+}
+
+// Dedup wraps next so that a record identical to the previous one (same level, message and
+// attributes, including those added with WithAttrs and WithGroup; the time is ignored) is
+// suppressed when it comes within window of the last written copy.
+//
+// Suppressed records are reported by a RepeatedMessage record at the same level, with
+// repeated_msg, repeated (the count), first_repeat and last_repeat. It is written before
+// the next different record, before the next copy once the window is over, or when the
+// handler is flushed (see Flusher). A zero window returns next unchanged.
+func Dedup(next slog.Handler, window time.Duration) slog.Handler {
+	if window <= 0 {
+		return next
+	}
+
+	return &dedupHandler{next: next, s: &dedupState{window: window}}
+}
+
+func (h *dedupHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *dedupHandler) Handle(ctx context.Context, r slog.Record) error {
+	bp, _ := bufPool.Get().(*[]byte)
+	fp := h.fingerprint((*bp)[:0], r)
+
+	summary, keep := h.s.observe(h.next, fp, r)
+
+	*bp = fp
+	bufPool.Put(bp)
+
+	if summary != nil {
+		_ = summary.handler.Handle(ctx, summary.record)
+	}
+
+	if !keep {
+		return nil
+	}
+
+	return h.next.Handle(ctx, r)
+}
+
+func (h *dedupHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	prefix := append([]byte(nil), h.prefix...)
+	for _, a := range attrs {
+		prefix = appendFingerprint(prefix, a)
+	}
+
+	return &dedupHandler{next: h.next.WithAttrs(attrs), prefix: prefix, s: h.s}
+}
+
+func (h *dedupHandler) WithGroup(name string) slog.Handler {
+	prefix := append([]byte(nil), h.prefix...)
+	prefix = append(prefix, "\x00group:"...)
+	prefix = append(prefix, name...)
+
+	return &dedupHandler{next: h.next.WithGroup(name), prefix: prefix, s: h.s}
+}
+
+// Flush writes the pending repeat report, if any, then flushes next.
+func (h *dedupHandler) Flush(ctx context.Context) error {
+	h.s.mu.Lock()
+	summary := h.s.summary()
+	h.s.mu.Unlock()
+
+	if summary != nil {
+		_ = summary.handler.Handle(ctx, summary.record)
+	}
+
+	return Flush(ctx, h.next)
+}
+
+// fingerprint identifies a record by everything but its time.
+func (h *dedupHandler) fingerprint(buf []byte, r slog.Record) []byte {
+	buf = append(buf, h.prefix...)
+	buf = append(buf, "\x00level:"...)
+	buf = strconv.AppendInt(buf, int64(r.Level), 10)
+	buf = append(buf, "\x00msg:"...)
+	buf = append(buf, r.Message...)
+
+	r.Attrs(func(a slog.Attr) bool {
+		buf = appendFingerprint(buf, a)
+		return true
+	})
+
+	return buf
+}
+
+func appendFingerprint(buf []byte, a slog.Attr) []byte {
+	buf = append(buf, 0)
+	buf = append(buf, a.Key...)
+	buf = append(buf, '=')
+
+	return append(buf, a.Value.Resolve().String()...)
+}
+
+// pendingSummary is a summary record and the handler to write it with.
+type pendingSummary struct {
+	handler slog.Handler
+	record  slog.Record
+}
+
+// observe records r and reports whether to write it, plus the summary of suppressed
+// duplicates to write before it, if any.
+func (s *dedupState) observe(next slog.Handler, fp []byte, r slog.Record) (*pendingSummary, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	same := bytes.Equal(fp, s.last)
+	if same && r.Time.Sub(s.since) < s.window {
+		if s.count == 0 {
+			s.first = r.Time
+		}
+
+		s.count++
+		s.final = r.Time
+
+		return nil, false
+	}
+
+	summary := s.summary()
+
+	if !same {
+		s.last = append(s.last[:0], fp...)
+		s.level, s.msg = r.Level, r.Message
+	}
+
+	s.since, s.handler = r.Time, next
+
+	return summary, true
+}
+
+// summary returns the pending repeat report and resets the count; the caller holds mu.
+func (s *dedupState) summary() *pendingSummary {
+	if s.count == 0 {
+		return nil
+	}
+
+	r := slog.NewRecord(s.final, s.level, RepeatedMessage, 0)
+	r.AddAttrs(
+		slog.String("repeated_msg", s.msg),
+		slog.Int("repeated", s.count),
+		slog.Time("first_repeat", s.first),
+		slog.Time("last_repeat", s.final),
+	)
+
+	s.count = 0
+
+	return &pendingSummary{handler: s.handler, record: r}
+}
@@ -0,0 +1,109 @@
+package handlers_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-logger/logger/handlers"
+)
+
+// TestDedupCollapsesConsecutiveDuplicates ensures repeats are counted and reported before the next record.
+func TestDedupCollapsesConsecutiveDuplicates(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := slog.New(handlers.Dedup(handlers.JSON(&buf, slog.HandlerOptions{}), time.Minute)).With("service", "svc")
+
+	for range 5 {
+		l.Error("db down", "host", "db-1")
+	}
+
+	l.Error("db down", "host", "db-2")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected original, summary and the different record, got %d: %s", len(lines), buf.String())
+	}
+
+	summary := decodeLine(t, bytes.NewBufferString(lines[1]))
+	if summary["msg"] != handlers.RepeatedMessage || summary["repeated"] != float64(4) ||
+		summary["repeated_msg"] != "db down" || summary["level"] != "ERROR" || summary["service"] != "svc" {
+		t.Fatalf("unexpected summary: %v", summary)
+	}
+
+	if summary["first_repeat"] == nil || summary["last_repeat"] == nil {
+		t.Fatalf("expected first and last repeat times: %v", summary)
+	}
+
+	if !strings.Contains(lines[2], `"host":"db-2"`) {
+		t.Fatalf("expected records with different fields to pass: %s", lines[2])
+	}
+}
+
+// TestDedupFlushWritesRepeats ensures Flush reports pending repeats and flushes the wrapped handler.
+func TestDedupFlushWritesRepeats(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.Sample(handlers.JSON(&buf, slog.HandlerOptions{}), handlers.SamplingOptions{Interval: time.Hour, First: 1})
+	l := slog.New(handlers.Dedup(h, time.Minute))
+
+	for i := range 3 {
+		l.Info("noisy", "n", i)
+	}
+
+	for range 5 {
+		l.Error("db down")
+	}
+
+	if err := handlers.Flush(t.Context(), l.Handler()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `"repeated_msg":"db down","repeated":4`) {
+		t.Fatalf("expected the repeat report on flush: %s", out)
+	}
+
+	if !strings.Contains(out, `"sampled_msg":"noisy","dropped":2`) {
+		t.Fatalf("expected flush to reach the sampler: %s", out)
+	}
+
+	buf.Reset()
+
+	if err := handlers.Flush(t.Context(), l.Handler()); err != nil || buf.Len() != 0 {
+		t.Fatalf("expected nothing left to flush: %v %s", err, buf.String())
+	}
+}
+
+// TestDedupWritesAgainAfterWindow ensures long outages are still visible periodically.
+func TestDedupWritesAgainAfterWindow(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := slog.New(handlers.Dedup(handlers.JSON(&buf, slog.HandlerOptions{}), 20*time.Millisecond))
+
+	l.Warn("retrying")
+	l.Warn("retrying")
+	time.Sleep(30 * time.Millisecond)
+	l.Warn("retrying")
+
+	out := buf.String()
+	if strings.Count(out, `"msg":"retrying"`) != 2 || !strings.Contains(out, `"repeated":1`) {
+		t.Fatalf("expected the record again with a summary after the window: %s", out)
+	}
+}
+
+// TestDedupDistinguishesPersistentAttrs ensures loggers with different attrs are not merged.
+func TestDedupDistinguishesPersistentAttrs(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := slog.New(handlers.Dedup(handlers.JSON(&buf, slog.HandlerOptions{}), time.Minute))
+
+	l.With("worker", 1).Info("tick")
+	l.With("worker", 2).Info("tick")
+
+	if got := strings.Count(buf.String(), `"msg":"tick"`); got != 2 {
+		t.Fatalf("expected both workers to be written, got %d: %s", got, buf.String())
+	}
+}
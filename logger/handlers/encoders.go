@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// maxSafeInt is the largest integer a JavaScript number (float64) holds exactly, 2^53-1.
+const maxSafeInt = 1<<53 - 1
+
+// Encoders is a registry mapping Go types to value encoders, applied to every attribute,
+// including those inside groups, through ReplaceAttr. Types are matched exactly against the
+// value's dynamic type; time.Duration, int64, uint64, float64, string, bool and time.Time
+// also match values stored with the corresponding slog kind.
+//
+// Register encoders before the registry is passed to a handler; it is read concurrently
+// afterwards. A nil *Encoders encodes nothing.
+type Encoders struct {
+	byType map[reflect.Type]func(slog.Value) slog.Value
+}
+
+// NewEncoders returns an empty registry.
+func NewEncoders() *Encoders {
+	return &Encoders{byType: map[reflect.Type]func(slog.Value) slog.Value{}}
+}
+
+// DefaultEncoders returns a registry producing output any JSON consumer can parse:
+// durations as strings ("1.5s"), []byte as base64, integers beyond ±(2^53-1) as strings,
+// NaN and ±Inf as strings, *big.Int as a decimal string, net.IP as text and url.URL
+// without its password.
+func DefaultEncoders() *Encoders {
+	e := NewEncoders()
+
+	Register(e, DurationString)
+	Register(e, BytesBase64)
+	Register(e, SafeInt64)
+	Register(e, SafeUint64)
+	Register(e, FiniteFloat)
+	Register(e, BigInt)
+	Register(e, IPString)
+	Register(e, URLString)
+	Register(e, func(u *url.URL) slog.Value {
+		if u == nil {
+			return slog.AnyValue(nil)
+		}
+
+		return URLString(*u)
+	})
+
+	return e
+}
+
+// Register sets the encoder for values of type T, replacing any previous one.
+func Register[T any](e *Encoders, fn func(T) slog.Value) {
+	e.byType[reflect.TypeFor[T]()] = func(v slog.Value) slog.Value {
+		t, ok := v.Any().(T)
+		if !ok {
+			return v
+		}
+
+		return fn(t)
+	}
+}
+
+// ReplaceAttr returns a slog.HandlerOptions.ReplaceAttr function applying the encoders
+// after next. next may be nil. A nil or empty registry returns next unchanged.
+func (e *Encoders) ReplaceAttr(next func([]string, slog.Attr) slog.Attr) func([]string, slog.Attr) slog.Attr {
+	if e == nil || len(e.byType) == 0 {
+		return next
+	}
+
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if next != nil {
+			a = next(groups, a)
+		}
+
+		if t := valueType(a.Value); t != nil {
+			if encode, ok := e.byType[t]; ok {
+				a.Value = encode(a.Value)
+			}
+		}
+
+		return a
+	}
+}
+
+var (
+	int64Type    = reflect.TypeFor[int64]()
+	uint64Type   = reflect.TypeFor[uint64]()
+	float64Type  = reflect.TypeFor[float64]()
+	stringType   = reflect.TypeFor[string]()
+	boolType     = reflect.TypeFor[bool]()
+	durationType = reflect.TypeFor[time.Duration]()
+	timeType     = reflect.TypeFor[time.Time]()
+)
+
+// valueType returns the Go type a value is matched with, or nil for groups.
+func valueType(v slog.Value) reflect.Type {
+	switch v.Kind() {
+	case slog.KindAny:
+		return reflect.TypeOf(v.Any())
+	case slog.KindInt64:
+		return int64Type
+	case slog.KindUint64:
+		return uint64Type
+	case slog.KindFloat64:
+		return float64Type
+	case slog.KindString:
+		return stringType
+	case slog.KindBool:
+		return boolType
+	case slog.KindDuration:
+		return durationType
+	case slog.KindTime:
+		return timeType
+	default:
+		return nil
+	}
+}
+
+// DurationMillis encodes a duration as fractional milliseconds.
+func DurationMillis(d time.Duration) slog.Value {
+	return slog.Float64Value(float64(d) / float64(time.Millisecond))
+}
+
+// DurationString encodes a duration in time.Duration.String form, e.g. "1.5s".
+func DurationString(d time.Duration) slog.Value {
+	return slog.StringValue(d.String())
+}
+
+// BytesBase64 encodes bytes as standard base64.
+func BytesBase64(b []byte) slog.Value {
+	return slog.StringValue(base64.StdEncoding.EncodeToString(b))
+}
+
+// BytesHex encodes bytes as lowercase hex.
+func BytesHex(b []byte) slog.Value {
+	return slog.StringValue(hex.EncodeToString(b))
+}
+
+// SafeInt64 encodes integers a float64 cannot hold exactly as decimal strings.
+func SafeInt64(n int64) slog.Value {
+	if n > maxSafeInt || n < -maxSafeInt {
+		return slog.StringValue(strconv.FormatInt(n, 10))
+	}
+
+	return slog.Int64Value(n)
+}
+
+// SafeUint64 encodes integers a float64 cannot hold exactly as decimal strings.
+func SafeUint64(n uint64) slog.Value {
+	if n > maxSafeInt {
+		return slog.StringValue(strconv.FormatUint(n, 10))
+	}
+
+	return slog.Uint64Value(n)
+}
+
+// FiniteFloat encodes NaN and ±Inf as the strings "NaN", "+Inf" and "-Inf".
+func FiniteFloat(f float64) slog.Value {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return slog.StringValue(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+
+	return slog.Float64Value(f)
+}
+
+// BigInt encodes a big integer as a decimal string.
+func BigInt(n *big.Int) slog.Value {
+	if n == nil {
+		return slog.AnyValue(nil)
+	}
+
+	return slog.StringValue(n.String())
+}
+
+// IPString encodes an IP address in its text form.
+func IPString(ip net.IP) slog.Value {
+	return slog.StringValue(ip.String())
+}
+
+// URLString encodes a URL without its password; the user name is kept.
+func URLString(u url.URL) slog.Value {
+	if u.User != nil {
+		u.User = url.User(u.User.Username())
+	}
+
+	return slog.StringValue(u.String())
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+)
+
+// field is an attribute flattened to a dotted key ("req.id") with a resolved value.
+type field struct {
+	key   string
+	value slog.Value
+}
+
+// flatRecord is a record after ReplaceAttr and group flattening, ready for encoding.
+// A built-in field with an empty key was removed by ReplaceAttr and must not be written.
+type flatRecord struct {
+	time   field
+	level  field
+	msg    field
+	source field
+	fields []field
+}
+
+// encoder renders a flatRecord into buf; implementations are the line formats of this package.
+type encoder interface {
+	encode(buf []byte, rec *flatRecord) []byte
+}
+
+// flatHandler implements slog.Handler for formats that write groups as dotted keys
+// in a deterministic order: time, level, msg, source, then attributes in insertion order.
+type flatHandler struct {
+	opts   slog.HandlerOptions
+	enc    encoder
+	w      io.Writer
+	mu     *sync.Mutex // shared by derived handlers writing to the same w
+	groups []string
+	prefix string  // groups joined with "." plus a trailing dot
+	attrs  []field // persistent attributes, already flattened
+}
+
+var bufPool = sync.Pool{New: func() any {
+	const initialSize = 1024
+
+	b := make([]byte, 0, initialSize)
+
+	return &b
+}}
+
+func newFlatHandler(w io.Writer, opts slog.HandlerOptions, enc encoder) *flatHandler {
+	return &flatHandler{opts: opts, enc: enc, w: w, mu: &sync.Mutex{}}
+}
+
+func (h *flatHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+
+	return level >= minLevel
+}
+
+func (h *flatHandler) Handle(_ context.Context, r slog.Record) error {
+	rec := flatRecord{
+		level: h.builtin(slog.LevelKey, slog.AnyValue(r.Level)),
+		msg:   h.builtin(slog.MessageKey, slog.StringValue(r.Message)),
+	}
+
+	if !r.Time.IsZero() {
+		rec.time = h.builtin(slog.TimeKey, slog.TimeValue(r.Time.Round(0)))
+	}
+
+	if h.opts.AddSource && r.PC != 0 {
+		rec.source = h.builtin(slog.SourceKey, slog.AnyValue(recordSource(r.PC)))
+	}
+
+	rec.fields = make([]field, 0, len(h.attrs)+r.NumAttrs())
+	rec.fields = append(rec.fields, h.attrs...)
+
+	r.Attrs(func(a slog.Attr) bool {
+		rec.fields = h.appendAttr(rec.fields, h.groups, h.prefix, a)
+		return true
+	})
+
+	bp, _ := bufPool.Get().(*[]byte)
+	buf := h.enc.encode((*bp)[:0], &rec)
+
+	h.mu.Lock()
+	_, err := h.w.Write(buf)
+	h.mu.Unlock()
+
+	*bp = buf
+	bufPool.Put(bp)
+
+	return err
+}
+
+func (h *flatHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	h2 := *h
+	h2.attrs = slices.Clip(h.attrs)
+
+	for _, a := range attrs {
+		h2.attrs = h.appendAttr(h2.attrs, h.groups, h.prefix, a)
+	}
+
+	return &h2
+}
+
+func (h *flatHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.groups = append(slices.Clip(h.groups), name)
+	h2.prefix = h.prefix + name + "."
+
+	return &h2
+}
+
+// builtin applies ReplaceAttr to a built-in attribute.
+func (h *flatHandler) builtin(key string, v slog.Value) field {
+	a := slog.Attr{Key: key, Value: v}
+	if h.opts.ReplaceAttr != nil {
+		a = h.opts.ReplaceAttr(nil, a)
+		a.Value = a.Value.Resolve()
+	}
+
+	return field{key: a.Key, value: a.Value}
+}
+
+// appendAttr flattens a into dst following slog's rules: values are resolved, empty
+// attributes and empty groups are dropped and groups with an empty key are inlined.
+func (h *flatHandler) appendAttr(dst []field, groups []string, prefix string, a slog.Attr) []field {
+	a.Value = a.Value.Resolve()
+
+	if h.opts.ReplaceAttr != nil && a.Value.Kind() != slog.KindGroup {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+
+	if a.Equal(slog.Attr{}) {
+		return dst
+	}
+
+	if a.Value.Kind() != slog.KindGroup {
+		return append(dst, field{key: prefix + a.Key, value: a.Value})
+	}
+
+	if a.Key != "" {
+		groups = append(slices.Clip(groups), a.Key)
+		prefix = prefix + a.Key + "."
+	}
+
+	for _, ga := range a.Value.Group() {
+		dst = h.appendAttr(dst, groups, prefix, ga)
+	}
+
+	return dst
+}
+
+// recordSource resolves the caller location of a record.
+func recordSource(pc uintptr) *slog.Source {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+	return &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+}
+
+// textTimeFormat matches slog's TextHandler time layout.
+const textTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// appendTime formats t with textTimeFormat.
+func appendTime(buf []byte, t time.Time) []byte {
+	return t.AppendFormat(buf, textTimeFormat)
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Flusher is implemented by handlers holding reports back until a later record arrives,
+// such as the summaries written by Sample, Dedup and Budget. Flush writes them now. The
+// wrappers in this package forward Flush to the handler they wrap, in any order.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Flush flushes h when it is a Flusher and does nothing otherwise.
+func Flush(ctx context.Context, h slog.Handler) error {
+	if f, ok := h.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import "log/slog"
+
+// Built-in keys emitted by this library on top of slog's time, level, msg and source.
+const (
+	KeyService = "service"
+	KeyTraceID = "trace_id"
+	KeySpanID  = "span_id"
+	KeyError   = "error"
+
+	// KeyMsgTemplate holds the template a message was rendered from; Sample groups records
+	// by it instead of by the rendered message.
+	KeyMsgTemplate = "msg_template"
+)
+
+// reservedKeys holds the keys every handler writes itself.
+var reservedKeys = map[string]struct{}{
+	slog.TimeKey:    {},
+	slog.LevelKey:   {},
+	slog.MessageKey: {},
+	slog.SourceKey:  {},
+}
+
+// IsReserved reports whether key is written by the handler itself (time, level, msg,
+// source), so a caller attribute with that key would produce a duplicate.
+func IsReserved(key string) bool {
+	_, ok := reservedKeys[key]
+
+	return ok
+}
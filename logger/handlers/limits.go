@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"unicode/utf8"
+)
+
+// truncatedSuffix is appended to an attribute key to report the original size of a truncated value.
+const truncatedSuffix = "_truncated"
+
+// Limits bounds the size of a single record. A zero field means "no limit".
+//
+// Truncated values are never dropped silently: a sibling attribute named
+// "<key>_truncated" carrying the original size (bytes or element count) is added next
+// to every shortened value, "msg_truncated" is added when the message is shortened and
+// "attrs_truncated" reports the original attribute count when attributes were capped.
+type Limits struct {
+	MaxMessageLength    int // bytes of the record message
+	MaxStringLength     int // bytes of any string value
+	MaxBytesLength      int // length of any []byte value
+	MaxCollectionLength int // elements of any slice, array or map value
+	MaxAttrs            int // top-level attributes per record, persistent ones included
+}
+
+// limitHandler enforces Limits before delegating to the wrapped handler.
+type limitHandler struct {
+	next    slog.Handler
+	limits  Limits
+	attrs   int // top-level attributes already attached through WithAttrs
+	dropped int // attributes passed to WithAttrs beyond MaxAttrs
+}
+
+// Limit wraps next so that every record is bounded by limits.
+// When limits is the zero value next is returned unchanged.
+func Limit(next slog.Handler, limits Limits) slog.Handler {
+	if limits == (Limits{}) {
+		return next
+	}
+
+	return &limitHandler{next: next, limits: limits}
+}
+
+func (h *limitHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *limitHandler) Handle(ctx context.Context, r slog.Record) error {
+	msg, msgSize, msgCut := truncateString(r.Message, h.limits.MaxMessageLength)
+
+	out := slog.NewRecord(r.Time, r.Level, msg, r.PC)
+
+	budget := h.budget()
+	total := 0
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		total++
+		if budget < 0 || total <= budget {
+			attrs = h.appendLimited(attrs, a)
+		}
+
+		return true
+	})
+
+	if msgCut {
+		attrs = append(attrs, slog.Int("msg"+truncatedSuffix, msgSize))
+	}
+
+	if h.dropped > 0 || (budget >= 0 && total > budget) {
+		attrs = append(attrs, slog.Int("attrs"+truncatedSuffix, h.attrs+h.dropped+total))
+	}
+
+	out.AddAttrs(attrs...)
+
+	return h.next.Handle(ctx, out)
+}
+
+func (h *limitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	dropped := h.dropped
+
+	budget := h.budget()
+	if budget >= 0 && len(attrs) > budget {
+		dropped += len(attrs) - budget
+		attrs = attrs[:budget]
+	}
+
+	limited := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		limited = h.appendLimited(limited, a)
+	}
+
+	return &limitHandler{next: h.next.WithAttrs(limited), limits: h.limits, attrs: h.attrs + len(attrs), dropped: dropped}
+}
+
+func (h *limitHandler) WithGroup(name string) slog.Handler {
+	return &limitHandler{next: h.next.WithGroup(name), limits: h.limits, attrs: h.attrs, dropped: h.dropped}
+}
+
+// Flush flushes the wrapped handler, see Flusher.
+func (h *limitHandler) Flush(ctx context.Context) error {
+	return Flush(ctx, h.next)
+}
+
+// budget returns how many more top-level attributes fit in a record, or -1 when unbounded.
+func (h *limitHandler) budget() int {
+	if h.limits.MaxAttrs <= 0 {
+		return -1
+	}
+
+	return max(h.limits.MaxAttrs-h.attrs, 0)
+}
+
+// appendLimited appends a, shortened if needed, followed by its truncation marker.
+func (h *limitHandler) appendLimited(dst []slog.Attr, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if s, size, cut := truncateString(a.Value.String(), h.limits.MaxStringLength); cut {
+			return append(dst, slog.String(a.Key, s), slog.Int(a.Key+truncatedSuffix, size))
+		}
+	case slog.KindGroup:
+		group := a.Value.Group()
+
+		limited := make([]slog.Attr, 0, len(group))
+		for _, ga := range group {
+			limited = h.appendLimited(limited, ga)
+		}
+
+		return append(dst, slog.Attr{Key: a.Key, Value: slog.GroupValue(limited...)})
+	case slog.KindAny:
+		if v, size, cut := h.truncateAny(a.Value.Any()); cut {
+			return append(dst, slog.Any(a.Key, v), slog.Int(a.Key+truncatedSuffix, size))
+		}
+	default:
+	}
+
+	return append(dst, a)
+}
+
+// truncateAny shortens byte slices and collections, reporting the original length.
+func (h *limitHandler) truncateAny(v any) (any, int, bool) {
+	if b, ok := v.([]byte); ok {
+		if h.limits.MaxBytesLength > 0 && len(b) > h.limits.MaxBytesLength {
+			return b[:h.limits.MaxBytesLength], len(b), true
+		}
+
+		return v, 0, false
+	}
+
+	limit := h.limits.MaxCollectionLength
+	if limit <= 0 || v == nil {
+		return v, 0, false
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Len() <= limit {
+			return v, 0, false
+		}
+
+		// Copy element-wise: arrays held in an interface are not addressable and cannot be sliced.
+		out := reflect.MakeSlice(reflect.SliceOf(rv.Type().Elem()), limit, limit)
+		for i := range limit {
+			out.Index(i).Set(rv.Index(i))
+		}
+
+		return out.Interface(), rv.Len(), true
+	case reflect.Map:
+		if rv.Len() <= limit {
+			return v, 0, false
+		}
+
+		out := reflect.MakeMapWithSize(rv.Type(), limit)
+
+		iter := rv.MapRange()
+		for i := 0; i < limit && iter.Next(); i++ {
+			out.SetMapIndex(iter.Key(), iter.Value())
+		}
+
+		return out.Interface(), rv.Len(), true
+	default:
+		return v, 0, false
+	}
+}
+
+// truncateString cuts s to at most limit bytes without splitting a UTF-8 sequence.
+func truncateString(s string, limit int) (string, int, bool) {
+	if limit <= 0 || len(s) <= limit {
+		return s, len(s), false
+	}
+
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return s[:cut], len(s), true
+}
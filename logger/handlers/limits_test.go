@@ -0,0 +1,149 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/next-trace/scg-logger/logger/handlers"
+)
+
+func decodeLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	m := map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("unmarshal: %v line=%s", err, buf.String())
+	}
+
+	return m
+}
+
+// TestLimitTruncatesMessageAndStrings ensures long values are shortened and annotated.
+func TestLimitTruncatesMessageAndStrings(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.Limit(handlers.JSON(&buf, slog.HandlerOptions{}), handlers.Limits{
+		MaxMessageLength: 5,
+		MaxStringLength:  3,
+		MaxBytesLength:   2,
+	})
+	slog.New(h).Info("hello world", "body", "abcdef", "raw", []byte("xyz"), "short", "ok")
+
+	m := decodeLine(t, &buf)
+
+	if m["msg"] != "hello" || m["msg_truncated"] != float64(11) {
+		t.Fatalf("expected truncated message with original size: %v", m)
+	}
+
+	if m["body"] != "abc" || m["body_truncated"] != float64(6) {
+		t.Fatalf("expected truncated string with original size: %v", m)
+	}
+
+	if m["raw_truncated"] != float64(3) {
+		t.Fatalf("expected truncated bytes with original size: %v", m)
+	}
+
+	if _, ok := m["short_truncated"]; ok || m["short"] != "ok" {
+		t.Fatalf("did not expect short value to be truncated: %v", m)
+	}
+}
+
+// TestLimitTruncatesCollections ensures slices and maps are capped by element count.
+func TestLimitTruncatesCollections(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.Limit(handlers.JSON(&buf, slog.HandlerOptions{}), handlers.Limits{MaxCollectionLength: 2})
+	slog.New(h).Info("collections",
+		"ids", []int{1, 2, 3, 4},
+		"arr", [3]string{"a", "b", "c"},
+		"tags", map[string]int{"a": 1, "b": 2, "c": 3},
+	)
+
+	m := decodeLine(t, &buf)
+
+	if ids, ok := m["ids"].([]any); !ok || len(ids) != 2 || m["ids_truncated"] != float64(4) {
+		t.Fatalf("expected slice capped to 2 elements: %v", m)
+	}
+
+	if arr, ok := m["arr"].([]any); !ok || len(arr) != 2 || m["arr_truncated"] != float64(3) {
+		t.Fatalf("expected array capped to 2 elements: %v", m)
+	}
+
+	if tags, ok := m["tags"].(map[string]any); !ok || len(tags) != 2 || m["tags_truncated"] != float64(3) {
+		t.Fatalf("expected map capped to 2 entries: %v", m)
+	}
+}
+
+// TestLimitCapsAttributeCount ensures persistent and call-site attributes share the budget.
+func TestLimitCapsAttributeCount(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.Limit(handlers.JSON(&buf, slog.HandlerOptions{}), handlers.Limits{MaxAttrs: 3})
+	slog.New(h).With("service", "svc").Info("many", "a", 1, "b", 2, "c", 3, "d", 4)
+
+	m := decodeLine(t, &buf)
+
+	for _, k := range []string{"service", "a", "b"} {
+		if _, ok := m[k]; !ok {
+			t.Fatalf("expected %q kept within budget: %v", k, m)
+		}
+	}
+
+	if _, ok := m["c"]; ok {
+		t.Fatalf("did not expect attributes beyond the budget: %v", m)
+	}
+
+	if m["attrs_truncated"] != float64(5) {
+		t.Fatalf("expected original attribute count reported: %v", m)
+	}
+}
+
+// TestLimitCountsDroppedPersistentAttrs ensures attributes cut by WithAttrs are reported too.
+func TestLimitCountsDroppedPersistentAttrs(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.Limit(handlers.JSON(&buf, slog.HandlerOptions{}), handlers.Limits{MaxAttrs: 2})
+	l := slog.New(h).With("service", "svc", "region", "eu", "zone", "a").With("pod", "p-1")
+
+	l.Info("bare")
+
+	first := decodeLine(t, &buf)
+	if _, ok := first["zone"]; ok || first["region"] != "eu" || first["attrs_truncated"] != float64(4) {
+		t.Fatalf("expected dropped persistent attributes counted: %v", first)
+	}
+
+	buf.Reset()
+	l.Info("with fields", "a", 1)
+
+	if second := decodeLine(t, &buf); second["attrs_truncated"] != float64(5) {
+		t.Fatalf("expected persistent and call-site attributes counted: %v", second)
+	}
+}
+
+// TestLimitKeepsUTF8Valid ensures truncation never splits a multi-byte rune.
+func TestLimitKeepsUTF8Valid(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.Limit(handlers.JSON(&buf, slog.HandlerOptions{}), handlers.Limits{MaxStringLength: 2})
+	slog.New(h).Info("utf8", "v", "ééé")
+
+	if strings.Contains(buf.String(), `�`) {
+		t.Fatalf("expected valid UTF-8 after truncation: %s", buf.String())
+	}
+
+	if m := decodeLine(t, &buf); m["v"] != "é" {
+		t.Fatalf("expected truncation at rune boundary: %v", m)
+	}
+}
+
+// TestLimitZeroValueIsPassthrough ensures no wrapping happens without limits.
+func TestLimitZeroValueIsPassthrough(t *testing.T) {
+	base := handlers.JSON(&bytes.Buffer{}, slog.HandlerOptions{})
+
+	if got := handlers.Limit(base, handlers.Limits{}); got != base {
+		t.Fatal("expected zero limits to return the wrapped handler unchanged")
+	}
+}
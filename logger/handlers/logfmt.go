@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Logfmt returns a slog.Handler writing one logfmt line per record:
+//
+//	time=2024-01-02T15:04:05.000Z level=INFO msg="user login" req.id=42
+//
+// Groups are flattened into dotted keys and keys are written in a deterministic order
+// (time, level, msg, source, then attributes in insertion order). Values containing
+// spaces, quotes, '=' or control characters are quoted with Go escaping rules.
+func Logfmt(w io.Writer, opts slog.HandlerOptions) slog.Handler {
+	return newFlatHandler(w, opts, logfmtEncoder{})
+}
+
+// logfmtEncoder renders records as logfmt.
+type logfmtEncoder struct{}
+
+func (logfmtEncoder) encode(buf []byte, rec *flatRecord) []byte {
+	for _, f := range [...]field{rec.time, rec.level, rec.msg, rec.source} {
+		if f.key != "" {
+			buf = appendLogfmtPair(buf, f.key, f.value)
+		}
+	}
+
+	for _, f := range rec.fields {
+		buf = appendLogfmtPair(buf, f.key, f.value)
+	}
+
+	if len(buf) > 0 && buf[len(buf)-1] == ' ' {
+		buf = buf[:len(buf)-1]
+	}
+
+	return append(buf, '\n')
+}
+
+// appendLogfmtPair writes key=value followed by a separating space.
+func appendLogfmtPair(buf []byte, key string, v slog.Value) []byte {
+	buf = appendLogfmtKey(buf, key)
+	buf = append(buf, '=')
+	buf = appendLogfmtString(buf, valueString(v))
+
+	return append(buf, ' ')
+}
+
+// appendLogfmtKey writes key, replacing characters that would break tokenization with '_'.
+func appendLogfmtKey(buf []byte, key string) []byte {
+	if key == "" {
+		return append(buf, '_')
+	}
+
+	for _, r := range key {
+		if r == '=' || r == '"' || r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			r = '_'
+		}
+
+		buf = utf8.AppendRune(buf, r)
+	}
+
+	return buf
+}
+
+// appendLogfmtString writes s bare when it is a single safe token and quoted otherwise.
+func appendLogfmtString(buf []byte, s string) []byte {
+	if needsQuoting(s) {
+		return strconv.AppendQuote(buf, s)
+	}
+
+	return append(buf, s...)
+}
+
+// needsQuoting reports whether s cannot be written as a bare logfmt value.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	for _, r := range s {
+		if r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// valueString renders v the way slog's TextHandler does.
+func valueString(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindTime:
+		return string(appendTime(nil, v.Time()))
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindAny:
+		return anyString(v.Any())
+	default:
+		return v.String()
+	}
+}
+
+// anyString renders an arbitrary value, preferring its text representation.
+func anyString(x any) string {
+	switch t := x.(type) {
+	case nil:
+		return "<nil>"
+	case *slog.Source:
+		if t == nil {
+			return "<nil>"
+		}
+
+		return t.File + ":" + strconv.Itoa(t.Line)
+	case error:
+		return t.Error()
+	case encoding.TextMarshaler:
+		b, err := t.MarshalText()
+		if err != nil {
+			return "!ERROR:" + err.Error()
+		}
+
+		return string(b)
+	case []byte:
+		return string(t)
+	case time.Time:
+		return string(appendTime(nil, t))
+	default:
+		return fmt.Sprintf("%+v", x)
+	}
+}
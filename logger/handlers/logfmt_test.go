@@ -0,0 +1,72 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/next-trace/scg-logger/logger/handlers"
+)
+
+// TestLogfmtQuotingAndOrder ensures values are escaped and keys keep a deterministic order.
+func TestLogfmtQuotingAndOrder(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.Logfmt(&buf, slog.HandlerOptions{ReplaceAttr: dropTime})
+	slog.New(h).Info("user login",
+		"plain", "ok",
+		"spaced", "a b",
+		"quoted", `say "hi"`,
+		"eq", "a=b",
+		"newline", "x\ny",
+		"empty", "",
+		"err", errors.New("boom"),
+	)
+
+	want := `level=INFO msg="user login" plain=ok spaced="a b" quoted="say \"hi\"" eq="a=b" newline="x\ny" empty="" err=boom` + "\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected logfmt line:\n got %q\nwant %q", buf.String(), want)
+	}
+}
+
+// TestLogfmtFlattensGroups ensures groups from WithGroup and slog.Group become dotted keys.
+func TestLogfmtFlattensGroups(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.Logfmt(&buf, slog.HandlerOptions{ReplaceAttr: dropTime})
+	slog.New(h).With("svc", "api").WithGroup("req").Info("grouped", "id", 7, slog.Group("user", "name", "ann"))
+
+	want := `level=INFO msg=grouped svc=api req.id=7 req.user.name=ann` + "\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected logfmt line:\n got %q\nwant %q", buf.String(), want)
+	}
+}
+
+// TestLogfmtLevelAndSource ensures the level filter and source option are honoured.
+func TestLogfmtLevelAndSource(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.Logfmt(&buf, slog.HandlerOptions{Level: slog.LevelWarn, AddSource: true})
+	l := slog.New(h)
+	l.Info("filtered")
+	l.Warn("kept")
+
+	out := buf.String()
+	if strings.Contains(out, "filtered") || !strings.Contains(out, "msg=kept") {
+		t.Fatalf("expected only warn record: %s", out)
+	}
+
+	if !strings.Contains(out, "source=") || !strings.Contains(out, "logfmt_test.go:") {
+		t.Fatalf("expected source location: %s", out)
+	}
+}
+
+func dropTime(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+
+	return a
+}
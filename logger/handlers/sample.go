@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// defaultSampleInterval is used when SamplingOptions.Interval is zero.
+const defaultSampleInterval = time.Second
+
+// SampledMessage is the message of the summary record reporting sampled-out records.
+const SampledMessage = "log records sampled out"
+
+// SamplingOptions configures per-message sampling. Records are grouped by (level, message):
+// within each interval the first First records of a group pass, then only every
+// Thereafter-th one (none when Thereafter is zero). A record with a KeyMsgTemplate
+// attribute is grouped by that template instead, so messages rendered from one template
+// share a group.
+//
+// For every group that lost records, a summary record (SampledMessage with sampled_level,
+// sampled_msg and dropped) is written at the group's level once the interval is over,
+// together with the first record of a later interval, or when the handler is flushed
+// (see Flusher), which also starts a new interval. A group spans every handler derived
+// with WithAttrs and WithGroup, so its summary is written through the handler Sample
+// wrapped, without their attributes.
+type SamplingOptions struct {
+	Interval   time.Duration
+	First      int
+	Thereafter int
+}
+
+// sampleKey groups records for sampling.
+type sampleKey struct {
+	level slog.Level
+	msg   string
+}
+
+type sampleCount struct {
+	seen    int
+	dropped int
+}
+
+// sampler is the state shared by a sampling handler and every handler derived from it.
+type sampler struct {
+	opts   SamplingOptions
+	report slog.Handler // handler passed to Sample, writes the summaries
+	mu     sync.Mutex
+	window time.Time
+	counts map[sampleKey]*sampleCount
+}
+
+type sampleHandler struct {
+	next slog.Handler
+	s    *sampler
+}
+
+// Sample wraps next with per-message sampling, so one hot log statement cannot flood the
+// output. It works with any handler. A zero SamplingOptions returns next unchanged.
+func Sample(next slog.Handler, opts SamplingOptions) slog.Handler {
+	if opts.First <= 0 && opts.Thereafter <= 0 {
+		return next
+	}
+
+	if opts.Interval <= 0 {
+		opts.Interval = defaultSampleInterval
+	}
+
+	return &sampleHandler{next: next, s: &sampler{opts: opts, report: next, counts: map[sampleKey]*sampleCount{}}}
+}
+
+func (h *sampleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *sampleHandler) Handle(ctx context.Context, r slog.Record) error {
+	keep, summary := h.s.decide(r.Level, sampleMessage(r), time.Now())
+
+	for _, s := range summary {
+		_ = h.s.report.Handle(ctx, s)
+	}
+
+	if !keep {
+		return nil
+	}
+
+	return h.next.Handle(ctx, r)
+}
+
+func (h *sampleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sampleHandler{next: h.next.WithAttrs(attrs), s: h.s}
+}
+
+func (h *sampleHandler) WithGroup(name string) slog.Handler {
+	return &sampleHandler{next: h.next.WithGroup(name), s: h.s}
+}
+
+// Flush writes the summaries of the current interval and starts a new one, then flushes
+// next.
+func (h *sampleHandler) Flush(ctx context.Context) error {
+	h.s.mu.Lock()
+	summary := h.s.rollover(time.Now())
+	h.s.mu.Unlock()
+
+	for _, s := range summary {
+		_ = h.s.report.Handle(ctx, s)
+	}
+
+	return Flush(ctx, h.next)
+}
+
+// sampleMessage returns the template r was rendered from, or its message when it has none.
+func sampleMessage(r slog.Record) string {
+	msg := r.Message
+
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == KeyMsgTemplate && a.Value.Kind() == slog.KindString {
+			msg = a.Value.String()
+
+			return false
+		}
+
+		return true
+	})
+
+	return msg
+}
+
+// decide counts a record and reports whether to keep it, plus the summaries of the
+// previous interval when this record starts a new one.
+func (s *sampler) decide(level slog.Level, msg string, now time.Time) (bool, []slog.Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var summary []slog.Record
+
+	if now.Sub(s.window) >= s.opts.Interval {
+		summary = s.rollover(now)
+	}
+
+	key := sampleKey{level: level, msg: msg}
+
+	c := s.counts[key]
+	if c == nil {
+		c = &sampleCount{}
+		s.counts[key] = c
+	}
+
+	c.seen++
+
+	if c.seen <= s.opts.First {
+		return true, summary
+	}
+
+	if s.opts.Thereafter > 0 && (c.seen-s.opts.First)%s.opts.Thereafter == 0 {
+		return true, summary
+	}
+
+	c.dropped++
+
+	return false, summary
+}
+
+// rollover starts a new interval and returns summary records for groups that lost records.
+func (s *sampler) rollover(now time.Time) []slog.Record {
+	var summary []slog.Record
+
+	for key, c := range s.counts {
+		if c.dropped == 0 {
+			continue
+		}
+
+		r := slog.NewRecord(now, key.level, SampledMessage, 0)
+		r.AddAttrs(
+			slog.String("sampled_level", key.level.String()),
+			slog.String("sampled_msg", key.msg),
+			slog.Int("dropped", c.dropped),
+			slog.Duration("interval", s.opts.Interval),
+		)
+		summary = append(summary, r)
+	}
+
+	s.window = now
+	clear(s.counts)
+
+	return summary
+}
@@ -0,0 +1,121 @@
+package handlers_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-logger/logger/handlers"
+)
+
+// TestSampleFirstThenEveryNth ensures the burst passes and later records are thinned out per message.
+func TestSampleFirstThenEveryNth(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.Sample(handlers.JSON(&buf, slog.HandlerOptions{}), handlers.SamplingOptions{
+		Interval:   time.Hour,
+		First:      3,
+		Thereafter: 5,
+	})
+	l := slog.New(h)
+
+	for range 20 {
+		l.Warn("hot loop")
+	}
+
+	l.Warn("other message")
+
+	// 3 first + records 8, 13 and 18 + the unrelated message.
+	if got := strings.Count(buf.String(), `"msg":"hot loop"`); got != 6 {
+		t.Fatalf("expected 6 sampled records, got %d: %s", got, buf.String())
+	}
+
+	if !strings.Contains(buf.String(), "other message") {
+		t.Fatalf("expected other messages to be sampled independently: %s", buf.String())
+	}
+}
+
+// TestSampleEmitsSummaryAfterInterval ensures dropped records are reported once the interval ends.
+func TestSampleEmitsSummaryAfterInterval(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.Sample(handlers.JSON(&buf, slog.HandlerOptions{}), handlers.SamplingOptions{
+		Interval: 20 * time.Millisecond,
+		First:    1,
+	})
+	l := slog.New(h).With("service", "svc")
+
+	for range 5 {
+		l.Warn("flood")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	l.Warn("flood")
+
+	out := buf.String()
+	if !strings.Contains(out, `"msg":"`+handlers.SampledMessage+`"`) || !strings.Contains(out, `"sampled_msg":"flood","dropped":4`) {
+		t.Fatalf("expected summary of dropped records: %s", out)
+	}
+
+	if got := strings.Count(out, `"msg":"flood"`); got != 2 {
+		t.Fatalf("expected the first record of each interval, got %d: %s", got, out)
+	}
+}
+
+// TestSampleFlushWritesSummary ensures Flush reports dropped records without waiting for
+// a later record, also through other wrappers.
+func TestSampleFlushWritesSummary(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.Sample(handlers.JSON(&buf, slog.HandlerOptions{}), handlers.SamplingOptions{Interval: time.Hour, First: 1})
+	h = handlers.NewValidator(handlers.ValidationOptions{Learn: true}).Handler(h)
+	l := slog.New(h).With("service", "svc")
+
+	for range 5 {
+		l.Warn("flood")
+	}
+
+	if err := handlers.Flush(t.Context(), l.Handler()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `"msg":"`+handlers.SampledMessage+`","sampled_level":"WARN","sampled_msg":"flood","dropped":4`) {
+		t.Fatalf("expected summary of dropped records on flush, without derived attributes: %s", out)
+	}
+
+	buf.Reset()
+	l.Warn("flood")
+
+	if err := handlers.Flush(t.Context(), l.Handler()); err != nil || buf.String() == "" || strings.Contains(buf.String(), handlers.SampledMessage) {
+		t.Fatalf("expected flush to start a new interval: %v %s", err, buf.String())
+	}
+}
+
+// TestSampleZeroOptionsIsPassthrough ensures sampling is off by default.
+func TestSampleZeroOptionsIsPassthrough(t *testing.T) {
+	base := handlers.JSON(&bytes.Buffer{}, slog.HandlerOptions{})
+
+	if handlers.Sample(base, handlers.SamplingOptions{}) != base {
+		t.Fatal("expected zero options to return the wrapped handler unchanged")
+	}
+}
+
+// TestSampleGroupsByMessageTemplate ensures records carrying a template are sampled by it.
+func TestSampleGroupsByMessageTemplate(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := slog.New(handlers.Sample(handlers.JSON(&buf, slog.HandlerOptions{}), handlers.SamplingOptions{Interval: time.Hour, First: 1}))
+
+	for _, id := range []string{"a", "b", "c"} {
+		l.Info("order "+id+" shipped", handlers.KeyMsgTemplate, "order {id} shipped", "id", id)
+	}
+
+	l.Info("order d shipped")
+
+	if got := strings.Count(buf.String(), "\n"); got != 2 {
+		t.Fatalf("expected one templated record and the plain one, got %d: %s", got, buf.String())
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Schema remaps the built-in keys (time, level, msg, source, service, trace_id, span_id,
+// error) and their value formats to the conventions expected by a log backend.
+//
+// Only top-level attributes are remapped; keys inside groups are left untouched.
+// Use one of the presets (SchemaECS, SchemaGCP, SchemaDatadog, SchemaOTel) or build a
+// custom mapping:
+//
+//	handlers.Schema{Keys: map[string]string{slog.MessageKey: "message"}}
+type Schema struct {
+	// Keys maps a built-in key to its output name. Missing keys are kept as is.
+	Keys map[string]string
+	// Values maps a built-in key to a value formatter applied before renaming.
+	Values map[string]func(slog.Value) slog.Value
+}
+
+// ReplaceAttr returns a slog.HandlerOptions.ReplaceAttr function applying the schema after next.
+// next may be nil. A zero Schema returns next unchanged.
+func (s Schema) ReplaceAttr(next func([]string, slog.Attr) slog.Attr) func([]string, slog.Attr) slog.Attr {
+	if len(s.Keys) == 0 && len(s.Values) == 0 {
+		return next
+	}
+
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if next != nil {
+			a = next(groups, a)
+		}
+
+		if len(groups) > 0 {
+			return a
+		}
+
+		if format, ok := s.Values[a.Key]; ok {
+			a.Value = format(a.Value)
+		}
+
+		if key, ok := s.Keys[a.Key]; ok {
+			a.Key = key
+		}
+
+		return a
+	}
+}
+
+// OutputKeys returns the keys the schema renames built-in keys to, sorted. Callers must
+// not use them, or the output would hold the same key twice.
+func (s Schema) OutputKeys() []string {
+	keys := make([]string, 0, len(s.Keys))
+	for _, k := range s.Keys {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+// SchemaECS maps built-in keys to Elastic Common Schema field names.
+func SchemaECS() Schema {
+	return Schema{
+		Keys: map[string]string{
+			slog.TimeKey:    "@timestamp",
+			slog.LevelKey:   "log.level",
+			slog.MessageKey: "message",
+			slog.SourceKey:  "log.origin",
+			KeyService:      "service.name",
+			KeyTraceID:      "trace.id",
+			KeySpanID:       "span.id",
+			KeyError:        "error.message",
+		},
+		Values: map[string]func(slog.Value) slog.Value{
+			slog.LevelKey:  lowerLevel,
+			slog.SourceKey: sourceGroup("file.name", "file.line", "function"),
+		},
+	}
+}
+
+// SchemaGCP maps built-in keys to Google Cloud Logging structured payload fields.
+// When projectID is set, trace IDs are expanded to "projects/<id>/traces/<trace_id>" so
+// Cloud Logging links the entry to Cloud Trace.
+func SchemaGCP(projectID string) Schema {
+	values := map[string]func(slog.Value) slog.Value{
+		slog.LevelKey: gcpSeverity,
+	}
+
+	if projectID != "" {
+		values[KeyTraceID] = func(v slog.Value) slog.Value {
+			return slog.StringValue("projects/" + projectID + "/traces/" + v.String())
+		}
+	}
+
+	return Schema{
+		Keys: map[string]string{
+			slog.LevelKey:   "severity",
+			slog.MessageKey: "message",
+			slog.SourceKey:  "logging.googleapis.com/sourceLocation",
+			KeyTraceID:      "logging.googleapis.com/trace",
+			KeySpanID:       "logging.googleapis.com/spanId",
+		},
+		Values: values,
+	}
+}
+
+// SchemaDatadog maps built-in keys to Datadog reserved attributes. Trace and span IDs are
+// converted to the decimal 64-bit form Datadog uses to correlate logs with APM traces.
+func SchemaDatadog() Schema {
+	return Schema{
+		Keys: map[string]string{
+			slog.TimeKey:    "timestamp",
+			slog.LevelKey:   "status",
+			slog.MessageKey: "message",
+			slog.SourceKey:  "logger",
+			KeyTraceID:      "dd.trace_id",
+			KeySpanID:       "dd.span_id",
+			KeyError:        "error.message",
+		},
+		Values: map[string]func(slog.Value) slog.Value{
+			slog.LevelKey:  lowerLevel,
+			slog.SourceKey: sourceGroup("file_name", "line", "method_name"),
+			KeyTraceID:     datadogID,
+			KeySpanID:      datadogID,
+		},
+	}
+}
+
+// SchemaOTel maps built-in keys to the OpenTelemetry log data model and semantic conventions.
+func SchemaOTel() Schema {
+	return Schema{
+		Keys: map[string]string{
+			slog.TimeKey:    "timestamp",
+			slog.LevelKey:   "severity_text",
+			slog.MessageKey: "body",
+			slog.SourceKey:  "code",
+			KeyService:      "service.name",
+			KeyError:        "exception.message",
+		},
+		Values: map[string]func(slog.Value) slog.Value{
+			slog.SourceKey: sourceGroup("filepath", "lineno", "function"),
+		},
+	}
+}
+
+// lowerLevel renders a level as lower-case text ("info", "warn", ...).
+func lowerLevel(v slog.Value) slog.Value {
+	if lvl, ok := v.Any().(slog.Level); ok {
+		return slog.StringValue(strings.ToLower(lvl.String()))
+	}
+
+	return v
+}
+
+// gcpSeverity renders a level using Cloud Logging LogSeverity names.
+func gcpSeverity(v slog.Value) slog.Value {
+	lvl, ok := v.Any().(slog.Level)
+	if !ok {
+		return v
+	}
+
+	switch {
+	case lvl < slog.LevelInfo:
+		return slog.StringValue("DEBUG")
+	case lvl < slog.LevelWarn:
+		return slog.StringValue("INFO")
+	case lvl < slog.LevelError:
+		return slog.StringValue("WARNING")
+	default:
+		return slog.StringValue("ERROR")
+	}
+}
+
+// datadogID converts a hex OTel ID to the decimal form of its lower 64 bits.
+func datadogID(v slog.Value) slog.Value {
+	const lower64 = 16
+
+	hex := v.String()
+	if len(hex) > lower64 {
+		hex = hex[len(hex)-lower64:]
+	}
+
+	id, err := strconv.ParseUint(hex, 16, 64)
+	if err != nil {
+		return v
+	}
+
+	return slog.StringValue(strconv.FormatUint(id, 10))
+}
+
+// sourceGroup renders *slog.Source as a group using the given file, line and function keys.
+func sourceGroup(fileKey, lineKey, funcKey string) func(slog.Value) slog.Value {
+	return func(v slog.Value) slog.Value {
+		src, ok := v.Any().(*slog.Source)
+		if !ok || src == nil {
+			return v
+		}
+
+		return slog.GroupValue(
+			slog.String(fileKey, src.File),
+			slog.Int(lineKey, src.Line),
+			slog.String(funcKey, src.Function),
+		)
+	}
+}
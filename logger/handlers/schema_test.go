@@ -0,0 +1,94 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/next-trace/scg-logger/logger/handlers"
+)
+
+func logWithSchema(t *testing.T, schema handlers.Schema) map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	h := handlers.JSON(&buf, slog.HandlerOptions{AddSource: true, ReplaceAttr: schema.ReplaceAttr(nil)})
+	slog.New(h).With(handlers.KeyService, "svc").Warn("schema line",
+		handlers.KeyTraceID, "4bf92f3577b34da6a3ce929d0e0e4736",
+		handlers.KeySpanID, "00f067aa0ba902b7",
+		handlers.KeyError, errors.New("boom").Error(),
+	)
+
+	return decodeLine(t, &buf)
+}
+
+func TestSchemaECS(t *testing.T) {
+	m := logWithSchema(t, handlers.SchemaECS())
+
+	if m["log.level"] != "warn" || m["message"] != "schema line" || m["service.name"] != "svc" {
+		t.Fatalf("unexpected ECS mapping: %v", m)
+	}
+
+	if _, ok := m["@timestamp"]; !ok || m["trace.id"] == nil || m["error.message"] != "boom" {
+		t.Fatalf("unexpected ECS mapping: %v", m)
+	}
+
+	if origin, ok := m["log.origin"].(map[string]any); !ok || origin["file.name"] == nil {
+		t.Fatalf("expected ECS log.origin group: %v", m)
+	}
+}
+
+func TestSchemaGCP(t *testing.T) {
+	m := logWithSchema(t, handlers.SchemaGCP("my-project"))
+
+	if m["severity"] != "WARNING" || m["message"] != "schema line" {
+		t.Fatalf("unexpected GCP mapping: %v", m)
+	}
+
+	want := "projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736"
+	if m["logging.googleapis.com/trace"] != want || m["logging.googleapis.com/spanId"] != "00f067aa0ba902b7" {
+		t.Fatalf("unexpected GCP trace mapping: %v", m)
+	}
+}
+
+func TestSchemaDatadog(t *testing.T) {
+	m := logWithSchema(t, handlers.SchemaDatadog())
+
+	// lower 64 bits of the trace ID and the span ID, in decimal.
+	if m["dd.trace_id"] != "11803532876627986230" || m["dd.span_id"] != "67667974448284343" {
+		t.Fatalf("unexpected Datadog trace mapping: %v", m)
+	}
+
+	if m["status"] != "warn" || m["message"] != "schema line" || m["service"] != "svc" {
+		t.Fatalf("unexpected Datadog mapping: %v", m)
+	}
+}
+
+func TestSchemaOTel(t *testing.T) {
+	m := logWithSchema(t, handlers.SchemaOTel())
+
+	if m["severity_text"] != "WARN" || m["body"] != "schema line" || m["exception.message"] != "boom" {
+		t.Fatalf("unexpected OTel mapping: %v", m)
+	}
+
+	if code, ok := m["code"].(map[string]any); !ok || code["lineno"] == nil {
+		t.Fatalf("expected OTel code group: %v", m)
+	}
+}
+
+func TestSchemaCustomLeavesGroupsUntouched(t *testing.T) {
+	var buf bytes.Buffer
+
+	schema := handlers.Schema{Keys: map[string]string{slog.MessageKey: "text", "id": "identifier"}}
+	h := handlers.JSON(&buf, slog.HandlerOptions{ReplaceAttr: schema.ReplaceAttr(nil)})
+	slog.New(h).Info("custom", "id", 1, slog.Group("req", "id", 2))
+
+	m := decodeLine(t, &buf)
+
+	req, ok := m["req"].(map[string]any)
+	if m["text"] != "custom" || m["identifier"] != float64(1) || !ok || req["id"] != float64(2) {
+		t.Fatalf("unexpected custom mapping: %v", m)
+	}
+}
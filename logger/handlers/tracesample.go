@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"log/slog"
+	"math"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceSamplingOptions configures trace-consistent sampling. The keep/drop decision is a
+// pure function of the record's trace, so every process keeps or drops the same traces.
+//
+// Records at or above KeepLevel are never sampled (the zero value keeps Info and above,
+// i.e. only debug records are sampled). Below it, a record whose ctx carries a trace ID is
+// kept when the trace falls within Ratio, or, with FollowSpan, when its span is sampled.
+// Without a trace ID the string returned by Key (e.g. a request ID) is hashed instead;
+// records with neither are kept. A Ratio outside (0, 1) keeps every trace.
+type TraceSamplingOptions struct {
+	Ratio      float64
+	KeepLevel  slog.Level
+	FollowSpan bool
+	Key        func(ctx context.Context) string
+}
+
+type traceSampleHandler struct {
+	next      slog.Handler
+	keepLevel slog.Level
+	follow    bool
+	threshold uint64 // keys hashing below it are kept; 0 keeps all
+	key       func(ctx context.Context) string
+}
+
+// TraceSample wraps next so that records below opts.KeepLevel are kept or dropped per trace
+// rather than per record. Zero options (no Ratio, no FollowSpan) return next unchanged.
+func TraceSample(next slog.Handler, opts TraceSamplingOptions) slog.Handler {
+	if (opts.Ratio <= 0 || opts.Ratio >= 1) && !opts.FollowSpan {
+		return next
+	}
+
+	h := &traceSampleHandler{next: next, keepLevel: opts.KeepLevel, follow: opts.FollowSpan, key: opts.Key}
+	if opts.Ratio > 0 && opts.Ratio < 1 {
+		h.threshold = uint64(opts.Ratio * math.MaxUint64)
+	}
+
+	return h
+}
+
+// Enabled drops sampled-out records before they are built.
+func (h *traceSampleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.keep(ctx, level) && h.next.Enabled(ctx, level)
+}
+
+func (h *traceSampleHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.keep(ctx, r.Level) {
+		return nil
+	}
+
+	return h.next.Handle(ctx, r)
+}
+
+func (h *traceSampleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.next = h.next.WithAttrs(attrs)
+
+	return &c
+}
+
+func (h *traceSampleHandler) WithGroup(name string) slog.Handler {
+	c := *h
+	c.next = h.next.WithGroup(name)
+
+	return &c
+}
+
+// Flush flushes the wrapped handler, see Flusher.
+func (h *traceSampleHandler) Flush(ctx context.Context) error {
+	return Flush(ctx, h.next)
+}
+
+// keep reports whether a record at level in ctx belongs to a sampled trace.
+func (h *traceSampleHandler) keep(ctx context.Context, level slog.Level) bool {
+	if level >= h.keepLevel {
+		return true
+	}
+
+	if ctx == nil {
+		return true
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		if h.follow && sc.HasSpanID() {
+			return sc.IsSampled()
+		}
+
+		// Like OTel's ratio sampler, use the random low 8 bytes of the trace ID.
+		tid := sc.TraceID()
+
+		return h.threshold == 0 || binary.BigEndian.Uint64(tid[8:]) < h.threshold
+	}
+
+	if h.key == nil || h.threshold == 0 {
+		return true
+	}
+
+	key := h.key(ctx)
+	if key == "" {
+		return true
+	}
+
+	f := fnv.New64a()
+	_, _ = f.Write([]byte(key))
+
+	return f.Sum64() < h.threshold
+}
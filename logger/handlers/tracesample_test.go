@@ -0,0 +1,114 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/next-trace/scg-logger/logger/handlers"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+func traceCtx(t *testing.T, i int, flags trace.TraceFlags) context.Context {
+	t.Helper()
+
+	// Spread the IDs like random trace IDs would be.
+	var tid trace.TraceID
+
+	binary.BigEndian.PutUint64(tid[:8], uint64(i))
+	binary.BigEndian.PutUint64(tid[8:], uint64(i+1)*0x9e3779b97f4a7c15)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: trace.SpanID{1}, TraceFlags: flags})
+
+	return trace.ContextWithSpanContext(t.Context(), sc)
+}
+
+// TestTraceSampleIsConsistentPerTrace ensures all debug records of a trace share one decision.
+func TestTraceSampleIsConsistentPerTrace(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := slog.New(handlers.TraceSample(handlers.JSON(&buf, slog.HandlerOptions{Level: slog.LevelDebug}),
+		handlers.TraceSamplingOptions{Ratio: 0.5}))
+
+	kept := 0
+
+	for i := range 200 {
+		ctx := traceCtx(t, i, 0)
+
+		buf.Reset()
+		l.DebugContext(ctx, "a")
+		l.DebugContext(ctx, "b")
+		l.InfoContext(ctx, "c")
+
+		switch n := strings.Count(buf.String(), "\n"); n {
+		case 3:
+			kept++
+		case 1:
+		default:
+			t.Fatalf("trace %d: expected all or none of its debug records, got %d lines", i, n)
+		}
+	}
+
+	if kept < 50 || kept > 150 {
+		t.Fatalf("expected roughly half of the traces kept, got %d/200", kept)
+	}
+}
+
+// TestTraceSampleFollowsSpanFlag ensures FollowSpan mirrors the tracing decision.
+func TestTraceSampleFollowsSpanFlag(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := slog.New(handlers.TraceSample(handlers.JSON(&buf, slog.HandlerOptions{Level: slog.LevelDebug}),
+		handlers.TraceSamplingOptions{FollowSpan: true}))
+
+	l.DebugContext(traceCtx(t, 1, trace.FlagsSampled), "sampled")
+	l.DebugContext(traceCtx(t, 2, 0), "not sampled")
+	l.DebugContext(t.Context(), "no trace")
+
+	out := buf.String()
+	if !strings.Contains(out, `"msg":"sampled"`) || strings.Contains(out, "not sampled") || !strings.Contains(out, "no trace") {
+		t.Fatalf("expected records to follow the span sampled flag: %s", out)
+	}
+}
+
+// TestTraceSampleFallsBackToKey ensures the request key drives the decision without a trace.
+func TestTraceSampleFallsBackToKey(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := slog.New(handlers.TraceSample(handlers.JSON(&buf, slog.HandlerOptions{Level: slog.LevelDebug}),
+		handlers.TraceSamplingOptions{
+			Ratio: 0.5,
+			Key: func(ctx context.Context) string {
+				id, _ := ctx.Value(requestIDKey{}).(string)
+				return id
+			},
+		}))
+
+	kept := 0
+
+	for i := range 200 {
+		ctx := context.WithValue(t.Context(), requestIDKey{}, fmt.Sprintf("req-%d", i))
+
+		buf.Reset()
+		l.DebugContext(ctx, "a")
+		l.DebugContext(ctx, "b")
+
+		switch strings.Count(buf.String(), "\n") {
+		case 2:
+			kept++
+		case 0:
+		default:
+			t.Fatalf("request %d: expected all or none of its records", i)
+		}
+	}
+
+	if kept == 0 || kept == 200 {
+		t.Fatalf("expected requests to be sampled, kept %d/200", kept)
+	}
+}
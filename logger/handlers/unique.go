@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// DefaultKeyPrefix is prepended to a key renamed by DuplicatePrefix or by reserved key protection.
+const DefaultKeyPrefix = "fields."
+
+// DuplicatePolicy decides which attribute survives when the same top-level key is set twice,
+// e.g. once through a context-derived logger and again at the call site.
+type DuplicatePolicy int
+
+const (
+	// DuplicateLastWins keeps the most recently added attribute (call site over context over persistent).
+	DuplicateLastWins DuplicatePolicy = iota
+	// DuplicateFirstWins keeps the earliest attribute and drops later ones.
+	DuplicateFirstWins
+	// DuplicatePrefix keeps every attribute, renaming later duplicates with a prefix (e.g. "fields.level").
+	DuplicatePrefix
+	// DuplicateAllow disables detection and emits duplicate keys as slog does by default.
+	DuplicateAllow
+)
+
+// uniqueHandler resolves collisions between persistent and call-site attributes.
+// Persistent attributes are applied to next eagerly, so a record without collisions is
+// passed through untouched; only a colliding record is rebuilt against base.
+type uniqueHandler struct {
+	base    slog.Handler // wrapped handler without the pending attributes
+	next    slog.Handler // base with pending applied
+	policy  DuplicatePolicy
+	prefix  string
+	pending []slog.Attr         // resolved top-level attributes applied to next
+	keys    map[string]struct{} // keys of pending
+}
+
+// UniqueKeys wraps next so that every record carries each top-level key at most once,
+// resolving collisions according to policy. An empty prefix defaults to DefaultKeyPrefix.
+// DuplicateAllow returns next unchanged.
+func UniqueKeys(next slog.Handler, policy DuplicatePolicy, prefix string) slog.Handler {
+	if policy == DuplicateAllow {
+		return next
+	}
+
+	if prefix == "" {
+		prefix = DefaultKeyPrefix
+	}
+
+	return &uniqueHandler{base: next, next: next, policy: policy, prefix: prefix}
+}
+
+func (h *uniqueHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *uniqueHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.collides(r) {
+		return h.next.Handle(ctx, r)
+	}
+
+	attrs := make([]slog.Attr, 0, len(h.pending)+r.NumAttrs())
+	attrs = append(attrs, h.pending...)
+
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	out.AddAttrs(resolveDuplicates(attrs, h.policy, h.prefix)...)
+
+	return h.base.Handle(ctx, out)
+}
+
+func (h *uniqueHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	pending := make([]slog.Attr, 0, len(h.pending)+len(attrs))
+	pending = append(pending, h.pending...)
+	pending = append(pending, attrs...)
+
+	h2 := &uniqueHandler{base: h.base, policy: h.policy, prefix: h.prefix}
+
+	if h.anyCollision(attrs) {
+		h2.pending = resolveDuplicates(pending, h.policy, h.prefix)
+		h2.next = h.base.WithAttrs(h2.pending)
+	} else {
+		h2.pending = pending
+		h2.next = h.next.WithAttrs(attrs)
+	}
+
+	h2.keys = make(map[string]struct{}, len(h2.pending))
+	for _, a := range h2.pending {
+		h2.keys[a.Key] = struct{}{}
+	}
+
+	return h2
+}
+
+// WithGroup seals the pending attributes: anything added afterwards is nested in the
+// group and can no longer collide with them.
+func (h *uniqueHandler) WithGroup(name string) slog.Handler {
+	next := h.next.WithGroup(name)
+
+	return &uniqueHandler{base: next, next: next, policy: h.policy, prefix: h.prefix}
+}
+
+// Flush flushes the wrapped handler, see Flusher.
+func (h *uniqueHandler) Flush(ctx context.Context) error {
+	return Flush(ctx, h.next)
+}
+
+// collides reports whether any record attribute repeats a pending key or another record key.
+func (h *uniqueHandler) collides(r slog.Record) bool {
+	if r.NumAttrs() == 0 {
+		return false
+	}
+
+	const inlineKeys = 16
+
+	var (
+		buf  [inlineKeys]string
+		seen = buf[:0]
+		hit  bool
+	)
+
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "" {
+			return true
+		}
+
+		if _, ok := h.keys[a.Key]; ok || slices.Contains(seen, a.Key) {
+			hit = true
+			return false
+		}
+
+		seen = append(seen, a.Key)
+
+		return true
+	})
+
+	return hit
+}
+
+// anyCollision reports whether attrs repeat a pending key or each other.
+func (h *uniqueHandler) anyCollision(attrs []slog.Attr) bool {
+	for i, a := range attrs {
+		if a.Key == "" {
+			continue
+		}
+
+		if _, ok := h.keys[a.Key]; ok {
+			return true
+		}
+
+		for _, b := range attrs[:i] {
+			if b.Key == a.Key {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// resolveDuplicates returns attrs with each key present once according to policy.
+// attrs is modified in place; the returned slice shares its backing array.
+func resolveDuplicates(attrs []slog.Attr, policy DuplicatePolicy, prefix string) []slog.Attr {
+	seen := make(map[string]int, len(attrs))
+	out := attrs[:0]
+
+	for _, a := range attrs {
+		if a.Key == "" {
+			// Empty keys are inlined groups or dropped by slog; never treat them as duplicates.
+			out = append(out, a)
+			continue
+		}
+
+		idx, dup := seen[a.Key]
+		if !dup {
+			seen[a.Key] = len(out)
+			out = append(out, a)
+
+			continue
+		}
+
+		switch policy {
+		case DuplicateFirstWins:
+		case DuplicatePrefix:
+			for dup {
+				a.Key = prefix + a.Key
+				_, dup = seen[a.Key]
+			}
+
+			seen[a.Key] = len(out)
+			out = append(out, a)
+		default:
+			out[idx].Value = a.Value
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,61 @@
+package handlers_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/next-trace/scg-logger/logger/handlers"
+)
+
+func logDuplicates(policy handlers.DuplicatePolicy) string {
+	var buf bytes.Buffer
+
+	h := handlers.UniqueKeys(handlers.JSON(&buf, slog.HandlerOptions{}), policy, "")
+	slog.New(h).With("user", "persistent").Info("dup", "user", "call-site")
+
+	return buf.String()
+}
+
+// TestUniqueKeysPolicies ensures each policy yields a single resolution for a repeated key.
+func TestUniqueKeysPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy handlers.DuplicatePolicy
+		want   string
+	}{
+		{"last wins", handlers.DuplicateLastWins, `"user":"call-site"}`},
+		{"first wins", handlers.DuplicateFirstWins, `"user":"persistent"}`},
+		{"prefix", handlers.DuplicatePrefix, `"user":"persistent","fields.user":"call-site"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := logDuplicates(tt.policy)
+
+			if strings.Count(out, `"user"`) != 1 || !strings.Contains(out, tt.want) {
+				t.Fatalf("expected %s, got %s", tt.want, out)
+			}
+		})
+	}
+}
+
+// TestUniqueKeysAllowKeepsDuplicates ensures DuplicateAllow restores slog's default behavior.
+func TestUniqueKeysAllowKeepsDuplicates(t *testing.T) {
+	if out := logDuplicates(handlers.DuplicateAllow); strings.Count(out, `"user"`) != 2 {
+		t.Fatalf("expected both attributes emitted: %s", out)
+	}
+}
+
+// TestUniqueKeysGroupsAreSeparateScopes ensures keys nested in a group do not collide with top-level keys.
+func TestUniqueKeysGroupsAreSeparateScopes(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := handlers.UniqueKeys(handlers.JSON(&buf, slog.HandlerOptions{}), handlers.DuplicateFirstWins, "")
+	slog.New(h).With("id", 1).WithGroup("req").Info("grouped", "id", 2)
+
+	if !strings.Contains(buf.String(), `"id":1,"req":{"id":2}`) {
+		t.Fatalf("expected grouped key kept separately: %s", buf.String())
+	}
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// FieldType is the type class of a value as log backends index it.
+type FieldType string
+
+// Field types. Integers and floats are all TypeNumber; TypeAny matches every value.
+const (
+	TypeString   FieldType = "string"
+	TypeNumber   FieldType = "number"
+	TypeBool     FieldType = "bool"
+	TypeTime     FieldType = "time"
+	TypeDuration FieldType = "duration"
+	TypeObject   FieldType = "object"
+	TypeArray    FieldType = "array"
+	TypeAny      FieldType = "any"
+)
+
+// ValidationOptions configures a Validator. Keys inside groups are dotted, e.g.
+// "http.status". Types fixes the type of a key; with Learn, the first type seen for any
+// other key becomes its rule. Required keys must be on every record, either on the record
+// itself or added with WithAttrs. Null values are not checked.
+type ValidationOptions struct {
+	Types       map[string]FieldType
+	Required    []string
+	Learn       bool
+	OnViolation func(Violation)
+}
+
+// Violation describes a record breaking a validation rule.
+type Violation struct {
+	Key     string
+	Want    FieldType // expected type, empty when Missing
+	Got     FieldType
+	Missing bool   // a required key is absent
+	Message string // message of the record, empty for attributes added with WithAttrs
+}
+
+func (v Violation) String() string {
+	if v.Missing {
+		return fmt.Sprintf("missing required key %q (msg %q)", v.Key, v.Message)
+	}
+
+	return fmt.Sprintf("key %q: got %s, want %s (msg %q)", v.Key, v.Got, v.Want, v.Message)
+}
+
+// Validator checks the keys and value types of records against a set of rules. Records
+// are never changed or dropped; violations are counted and passed to OnViolation.
+// A Validator is safe for concurrent use. A nil Validator checks nothing.
+type Validator struct {
+	opts       ValidationOptions
+	mu         sync.RWMutex
+	learned    map[string]FieldType
+	violations atomic.Uint64
+}
+
+// NewValidator returns a Validator applying opts.
+func NewValidator(opts ValidationOptions) *Validator {
+	return &Validator{opts: opts, learned: map[string]FieldType{}}
+}
+
+// TestingT is the part of testing.TB used by FailOnViolation.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// FailOnViolation returns an OnViolation callback failing the test t on every violation,
+// e.g. handlers.ValidationOptions{Learn: true, OnViolation: handlers.FailOnViolation(t)}.
+func FailOnViolation(t TestingT) func(Violation) {
+	return func(v Violation) {
+		t.Helper()
+		t.Errorf("log validation: %s", v)
+	}
+}
+
+// Violations returns the number of violations reported so far.
+func (v *Validator) Violations() uint64 {
+	if v == nil {
+		return 0
+	}
+
+	return v.violations.Load()
+}
+
+// Handler wraps next so that every record is validated before being handled.
+func (v *Validator) Handler(next slog.Handler) slog.Handler {
+	if v == nil {
+		return next
+	}
+
+	return &validateHandler{next: next, v: v}
+}
+
+// check validates the type of one attribute value.
+func (v *Validator) check(key string, val slog.Value, msg string) {
+	got := typeOf(val)
+	if got == "" {
+		return
+	}
+
+	want, ok := v.opts.Types[key]
+	if !ok {
+		v.mu.RLock()
+		want, ok = v.learned[key]
+		v.mu.RUnlock()
+	}
+
+	if !ok {
+		if v.opts.Learn && got != TypeAny {
+			v.mu.Lock()
+			if _, ok = v.learned[key]; !ok {
+				v.learned[key] = got
+			}
+			want = v.learned[key]
+			v.mu.Unlock()
+		} else {
+			return
+		}
+	}
+
+	if want == got || want == TypeAny || got == TypeAny {
+		return
+	}
+
+	v.report(Violation{Key: key, Want: want, Got: got, Message: msg})
+}
+
+func (v *Validator) report(violation Violation) {
+	v.violations.Add(1)
+
+	if v.opts.OnViolation != nil {
+		v.opts.OnViolation(violation)
+	}
+}
+
+type validateHandler struct {
+	next   slog.Handler
+	v      *Validator
+	prefix string   // dotted groups opened with WithGroup
+	keys   []string // dotted keys added with WithAttrs
+}
+
+func (h *validateHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *validateHandler) Handle(ctx context.Context, r slog.Record) error {
+	var seen []string
+
+	if len(h.v.opts.Required) > 0 {
+		seen = slices.Clone(h.keys)
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		seen = h.walk(h.prefix, a, r.Message, seen)
+		return true
+	})
+
+	for _, key := range h.v.opts.Required {
+		if !slices.Contains(seen, key) {
+			h.v.report(Violation{Key: key, Missing: true, Message: r.Message})
+		}
+	}
+
+	return h.next.Handle(ctx, r)
+}
+
+func (h *validateHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.next = h.next.WithAttrs(attrs)
+	c.keys = slices.Clip(h.keys)
+
+	for _, a := range attrs {
+		c.keys = h.walk(h.prefix, a, "", c.keys)
+	}
+
+	return &c
+}
+
+func (h *validateHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	c := *h
+	c.next = h.next.WithGroup(name)
+	c.prefix = h.prefix + name + "."
+
+	return &c
+}
+
+// Flush flushes the wrapped handler, see Flusher.
+func (h *validateHandler) Flush(ctx context.Context) error {
+	return Flush(ctx, h.next)
+}
+
+// walk validates a and its group members, appending the dotted keys seen to seen when
+// required keys are configured.
+func (h *validateHandler) walk(prefix string, a slog.Attr, msg string, seen []string) []string {
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			// A non-empty group is written as an object under its key, so it is typed like a leaf.
+			if len(a.Value.Group()) > 0 {
+				seen = h.checkKey(prefix+a.Key, a.Value, msg, seen)
+			}
+
+			prefix += a.Key + "."
+		}
+
+		for _, ga := range a.Value.Group() {
+			seen = h.walk(prefix, ga, msg, seen)
+		}
+
+		return seen
+	}
+
+	if a.Key == "" {
+		return seen
+	}
+
+	return h.checkKey(prefix+a.Key, a.Value, msg, seen)
+}
+
+// checkKey validates the value of the dotted key and records it in seen when required keys
+// are configured.
+func (h *validateHandler) checkKey(key string, val slog.Value, msg string, seen []string) []string {
+	h.v.check(key, val, msg)
+
+	if len(h.v.opts.Required) > 0 {
+		seen = append(seen, key)
+	}
+
+	return seen
+}
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// typeOf classifies a value; it returns "" for null values.
+func typeOf(v slog.Value) FieldType {
+	switch v.Kind() {
+	case slog.KindString:
+		return TypeString
+	case slog.KindInt64, slog.KindUint64, slog.KindFloat64:
+		return TypeNumber
+	case slog.KindBool:
+		return TypeBool
+	case slog.KindTime:
+		return TypeTime
+	case slog.KindDuration:
+		return TypeDuration
+	case slog.KindGroup:
+		return TypeObject
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok && err != nil {
+			return TypeString
+		}
+
+		return reflectType(reflect.ValueOf(v.Any()))
+	default:
+		return TypeAny
+	}
+}
+
+func reflectType(rv reflect.Value) FieldType {
+	if !rv.IsValid() {
+		return ""
+	}
+
+	if rv.Type().Implements(textMarshalerType) {
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return ""
+		}
+
+		return TypeString
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		return TypeString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return TypeNumber
+	case reflect.Bool:
+		return TypeBool
+	case reflect.Slice:
+		if rv.IsNil() {
+			return ""
+		}
+
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return TypeString // encoded as base64 text
+		}
+
+		return TypeArray
+	case reflect.Array:
+		return TypeArray
+	case reflect.Map:
+		if rv.IsNil() {
+			return ""
+		}
+
+		return TypeObject
+	case reflect.Struct:
+		return TypeObject
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return ""
+		}
+
+		return reflectType(rv.Elem())
+	default:
+		return TypeAny
+	}
+}
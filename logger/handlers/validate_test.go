@@ -0,0 +1,116 @@
+package handlers_test
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/next-trace/scg-logger/logger/handlers"
+)
+
+// TestValidatorLearnsTypes ensures a key logged with another type than first seen is reported.
+func TestValidatorLearnsTypes(t *testing.T) {
+	var (
+		buf        bytes.Buffer
+		violations []handlers.Violation
+	)
+
+	v := handlers.NewValidator(handlers.ValidationOptions{
+		Learn:       true,
+		OnViolation: func(vi handlers.Violation) { violations = append(violations, vi) },
+	})
+	l := slog.New(v.Handler(handlers.JSON(&buf, slog.HandlerOptions{})))
+
+	l.Info("login", "user_id", "u-1", slog.Group("http", "status", 200))
+	l.Info("logout", "user_id", 42, slog.Group("http", "status", 201))
+	l.Info("retry", "user_id", nil, slog.Group("http", "status", "500"))
+
+	if len(violations) != 2 || v.Violations() != 2 {
+		t.Fatalf("expected two violations, got %v", violations)
+	}
+
+	want := handlers.Violation{Key: "user_id", Want: handlers.TypeString, Got: handlers.TypeNumber, Message: "logout"}
+	if violations[0] != want {
+		t.Fatalf("unexpected violation: %+v", violations[0])
+	}
+
+	if violations[1].Key != "http.status" || violations[1].Got != handlers.TypeString {
+		t.Fatalf("expected nested keys to be checked and null values skipped: %+v", violations[1])
+	}
+
+	if strings.Count(buf.String(), "\n") != 3 {
+		t.Fatalf("expected records to be written unchanged: %s", buf.String())
+	}
+}
+
+// TestValidatorChecksGroupKeys ensures a key used as a leaf and as a group is reported.
+func TestValidatorChecksGroupKeys(t *testing.T) {
+	var violations []handlers.Violation
+
+	v := handlers.NewValidator(handlers.ValidationOptions{
+		Learn:       true,
+		OnViolation: func(vi handlers.Violation) { violations = append(violations, vi) },
+	})
+	l := slog.New(v.Handler(handlers.JSON(&bytes.Buffer{}, slog.HandlerOptions{})))
+
+	l.Info("first", "user", "ann")
+	l.Info("second", slog.Group("user", "id", 1))
+	l.Info("empty", slog.Group("user"))
+
+	want := handlers.Violation{Key: "user", Want: handlers.TypeString, Got: handlers.TypeObject, Message: "second"}
+	if len(violations) != 1 || violations[0] != want {
+		t.Fatalf("expected one group violation, got %+v", violations)
+	}
+}
+
+// TestValidatorConfiguredTypesAndRequiredKeys covers configured rules and persistent attrs.
+func TestValidatorConfiguredTypesAndRequiredKeys(t *testing.T) {
+	var got []string
+
+	v := handlers.NewValidator(handlers.ValidationOptions{
+		Types:       map[string]handlers.FieldType{"req.size": handlers.TypeNumber},
+		Required:    []string{"service", "req.size"},
+		OnViolation: func(vi handlers.Violation) { got = append(got, vi.String()) },
+	})
+	base := slog.New(v.Handler(handlers.JSON(&bytes.Buffer{}, slog.HandlerOptions{})))
+
+	l := base.With("service", "api").WithGroup("req")
+	l.Info("ok", "size", 10)
+	l.Info("bad type", "size", "10")
+	base.Info("no service", slog.Group("req", "size", 1))
+
+	want := []string{
+		`key "req.size": got string, want number (msg "bad type")`,
+		`missing required key "service" (msg "no service")`,
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// TestFailOnViolationReportsToTest ensures tests can fail on violations.
+func TestFailOnViolationReportsToTest(t *testing.T) {
+	rt := &recordingT{}
+
+	v := handlers.NewValidator(handlers.ValidationOptions{Learn: true, OnViolation: handlers.FailOnViolation(rt)})
+	l := slog.New(v.Handler(handlers.JSON(&bytes.Buffer{}, slog.HandlerOptions{})))
+
+	l.Info("a", "n", 1)
+	l.Info("b", "n", true)
+
+	if len(rt.errors) != 1 || !strings.Contains(rt.errors[0], `key "n": got bool, want number`) {
+		t.Fatalf("expected one test failure, got %q", rt.errors)
+	}
+}
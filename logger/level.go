@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"math"
+)
+
+// levelAll is the handler level used when the logger gates levels itself, so that a
+// per-request override can let records below the configured level through.
+const levelAll = slog.Level(math.MinInt)
+
+// levelOverrideKey is the zero-sized context key for a per-request level override.
+type levelOverrideKey struct{}
+
+// WithLevelOverride returns a context in which loggers from this package use level
+// ("debug", "info", "warn", "error") instead of their configured level, e.g. to log one
+// customer request at debug while the service stays at info. An invalid level returns
+// ctx unchanged.
+func WithLevelOverride(ctx context.Context, level string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	lvl, err := mapLevel(level)
+	if err != nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, levelOverrideKey{}, lvl)
+}
+
+// LevelOverride returns the level set by WithLevelOverride, if any.
+func LevelOverride(ctx context.Context) (slog.Level, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+
+	lvl, ok := ctx.Value(levelOverrideKey{}).(slog.Level)
+
+	return lvl, ok
+}
@@ -3,6 +3,11 @@ package logger
 import (
 	"context"
 	"log/slog"
+	"reflect"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/next-trace/scg-logger/contract"
 	ih "github.com/next-trace/scg-logger/logger/handlers"
@@ -12,12 +17,38 @@ import (
 // Ensure slogLogger implements the contract.Logger at compile time.
 var _ contract.Logger = (*slogLogger)(nil)
 
-// slogLogger is the default implementation wrapping *slog.Logger.
+// slogLogger is the default implementation. It drives a slog.Handler directly instead of a
+// *slog.Logger so that the level check happens before any argument processing and records
+// are built from pooled attribute buffers.
 type slogLogger struct {
-	core *slog.Logger
-	svc  string
+	handler   slog.Handler
+	level     slog.Level // configured minimum level, see enabled
+	svc       string
+	prefix    string              // renames caller keys colliding with built-in keys
+	reserved  map[string]struct{} // keys written by this logger on top of ih.IsReserved, see safeKey
+	fields    *fieldSet           // context fields this logger was derived from by For, if any
+	base      *slogLogger         // logger For was called on, when fields is set
+	addSource bool
+	autoCtx   bool        // add context fields on every call, see WithAutoContextFields
+	templates bool        // render {name} placeholders in messages, see WithMessageTemplates
+	extract   []Extractor // ctx -> attributes, in registry order
 }
 
+// attrPool recycles the attribute buffers used to build records on the hot path.
+var attrPool = sync.Pool{New: func() any {
+	const initialAttrs = 16
+
+	b := make([]slog.Attr, 0, initialAttrs)
+
+	return &b
+}}
+
+// callerSkip skips runtime.Callers, slogLogger.log and the *Ctx method.
+const callerSkip = 3
+
+// maxPooledAttrs keeps unusually large buffers from being retained by the pool.
+const maxPooledAttrs = 256
+
 // New creates a new Logger using functional options.
 // Defaults: JSON output, level=info, no caller.
 func New(opts ...Option) contract.Logger {
@@ -29,24 +60,46 @@ func New(opts ...Option) contract.Logger {
 		lvl = slog.LevelInfo
 	}
 
-	var h slog.Handler
-
-	options := slog.HandlerOptions{Level: lvl, AddSource: cfg.WithCaller}
-	writer := cfg.Writer
-
-	if cfg.Pretty {
-		h = ih.Text(writer, options)
-	} else {
-		h = ih.JSON(writer, options)
+	// The logger gates levels itself (see enabled) so per-request overrides can go below lvl.
+	options := slog.HandlerOptions{
+		Level:       levelAll,
+		AddSource:   cfg.WithCaller,
+		ReplaceAttr: cfg.Schema.ReplaceAttr(cfg.Encoders.ReplaceAttr(nil)),
 	}
+	h := newFormatHandler(cfg, options)
+	h = ih.Limit(h, cfg.Limits)
+	h = ih.UniqueKeys(h, cfg.DuplicateKeys, cfg.KeyPrefix)
+	h = cfg.Validator.Handler(h)
 
-	core := slog.New(h)
-	// Attach service if provided
+	// Attach service if provided. The handlers below write their summaries through the
+	// handler they wrap, so the summaries carry the service but no context fields from For.
 	if cfg.Service != "" {
-		core = core.With("service", cfg.Service)
+		h = h.WithAttrs([]slog.Attr{slog.String(ih.KeyService, cfg.Service)})
 	}
 
-	return &slogLogger{core: core, svc: cfg.Service}
+	h = cfg.Budget.Handler(h)
+	h = ih.Sample(h, cfg.Sampling)
+	h = ih.TraceSample(h, cfg.TraceSampling)
+	h = ih.Dedup(h, cfg.DedupWindow)
+
+	extractors := make([]Extractor, 0, len(cfg.Extractors))
+	for _, e := range cfg.Extractors {
+		if e.Extract != nil {
+			extractors = append(extractors, e.Extract)
+		}
+	}
+
+	return &slogLogger{
+		handler:   h,
+		level:     lvl,
+		svc:       cfg.Service,
+		prefix:    cfg.KeyPrefix,
+		reserved:  reservedKeys(cfg),
+		addSource: cfg.WithCaller,
+		autoCtx:   cfg.AutoContextFields,
+		templates: cfg.MessageTemplates,
+		extract:   extractors,
+	}
 }
 
 // MustInitDefault initializes and returns a logger, panicking on failure.
@@ -60,63 +113,257 @@ func MustInitDefault(opts ...Option) contract.Logger {
 	return l
 }
 
+// Flush writes the reports held back until a later record arrives: sampling summaries
+// (WithSampling), repeat counts (WithDedup) and shed reports (WithBudget). Call it before
+// the process exits, e.g. defer logger.Flush(ctx, l). Loggers not created by New are left
+// alone.
+func Flush(ctx context.Context, l contract.Logger) error {
+	if f, ok := l.(ih.Flusher); ok {
+		return f.Flush(ctx)
+	}
+
+	return nil
+}
+
+// Flush implements ih.Flusher, see the package-level Flush.
+func (l *slogLogger) Flush(ctx context.Context) error {
+	return ih.Flush(ctx, l.handler)
+}
+
 // For checks the context for structured fields and returns an enriched logger.
+// The derived logger is cached on the context fields, so calling For at every log site
+// is a lookup after the first call.
 func (l *slogLogger) For(ctx context.Context) contract.Logger {
-	if ctx == nil {
+	fs := fieldsFrom(ctx)
+	if fs == nil || len(fs.attrs) == 0 || fs == l.fields {
 		return l
 	}
 
-	if v := ctx.Value(logFieldsKey); v != nil {
-		if m, ok := v.(map[string]interface{}); ok && len(m) > 0 {
-			attrs := make([]any, 0, len(m)*2)
-			for k, val := range m {
-				attrs = append(attrs, k, val)
-			}
-			core := l.core.With(attrs...)
-			return &slogLogger{core: core, svc: l.svc}
-		}
+	// ctx's fields already include those of the contexts it was derived from, so a derived
+	// logger derives again from its root instead of applying its own fields twice.
+	if l.fields != nil {
+		return l.root().For(ctx)
 	}
-	return l
+
+	if c := fs.cache.Load(); c != nil && c.base == l {
+		return c.derived
+	}
+
+	attrs := make([]slog.Attr, len(fs.attrs))
+	copy(attrs, fs.attrs)
+	l.protectReserved(attrs)
+
+	derived := *l
+	derived.handler = l.handler.WithAttrs(attrs)
+	derived.fields = fs
+	derived.base = l
+
+	fs.cache.Store(&derivedLogger{base: l, derived: &derived})
+
+	return &derived
 }
 
-func (l *slogLogger) withCtx(ctx context.Context, kv []any) (context.Context, []any) {
-	// Correlate OTel trace/span if available
-	kv = addTraceKV(ctx, kv)
-	return ctx, kv
+// safeKey renames a caller-supplied key that would collide with a key the handler or the
+// logger writes itself. trace_id and span_id are not renamed: fields attached by middleware
+// carry them on purpose, and the duplicate policy settles a clash with the OTel extractor.
+func (l *slogLogger) safeKey(key string) string {
+	if _, ok := l.reserved[key]; ok || ih.IsReserved(key) {
+		return l.prefix + key
+	}
+
+	return key
 }
 
-func (l *slogLogger) DebugCtx(ctx context.Context, msg string, kv ...any) {
-	kv = utils.SanitizeKV(kv)
+// reservedKeys returns the keys a logger built from cfg writes besides time, level, msg and
+// source: service when it is set, and every output key of the schema.
+func reservedKeys(cfg Config) map[string]struct{} {
+	reserved := map[string]struct{}{}
 
-	ctx, kv = l.withCtx(ctx, kv)
+	if cfg.Service != "" {
+		reserved[ih.KeyService] = struct{}{}
+	}
 
-	l.core.DebugContext(ctx, msg, kv...)
+	for _, k := range cfg.Schema.OutputKeys() {
+		reserved[k] = struct{}{}
+	}
+
+	return reserved
 }
 
-func (l *slogLogger) InfoCtx(ctx context.Context, msg string, kv ...any) {
-	kv = utils.SanitizeKV(kv)
+// protectReserved renames reserved keys in attrs in place.
+func (l *slogLogger) protectReserved(attrs []slog.Attr) {
+	for i := range attrs {
+		attrs[i].Key = l.safeKey(attrs[i].Key)
+	}
+}
 
-	ctx, kv = l.withCtx(ctx, kv)
+func (l *slogLogger) DebugCtx(ctx context.Context, msg string, kv ...any) {
+	l.log(ctx, slog.LevelDebug, msg, nil, kv)
+}
 
-	l.core.InfoContext(ctx, msg, kv...)
+func (l *slogLogger) InfoCtx(ctx context.Context, msg string, kv ...any) {
+	l.log(ctx, slog.LevelInfo, msg, nil, kv)
 }
 
 func (l *slogLogger) WarnCtx(ctx context.Context, msg string, kv ...any) {
-	kv = utils.SanitizeKV(kv)
+	l.log(ctx, slog.LevelWarn, msg, nil, kv)
+}
+
+func (l *slogLogger) ErrorCtx(ctx context.Context, msg string, err error, kv ...any) {
+	l.log(ctx, slog.LevelError, msg, err, kv)
+}
+
+// appendContextFields appends the fields attached to ctx, skipping those this logger
+// already carries because it was derived from them with For.
+func (l *slogLogger) appendContextFields(ctx context.Context, attrs []slog.Attr) []slog.Attr {
+	fs := fieldsFrom(ctx)
+	if fs == nil || fs == l.fields {
+		return attrs
+	}
+
+	for _, a := range fs.attrs {
+		if l.fields != nil && slices.ContainsFunc(l.fields.attrs, func(b slog.Attr) bool { return sameAttr(a, b) }) {
+			continue
+		}
 
-	ctx, kv = l.withCtx(ctx, kv)
+		attrs = append(attrs, a)
+	}
 
-	l.core.WarnContext(ctx, msg, kv...)
+	return attrs
 }
 
-func (l *slogLogger) ErrorCtx(ctx context.Context, msg string, err error, kv ...any) {
-	kv = utils.SanitizeKV(kv)
+// withoutRemoved returns the logger to use with ctx: l itself, unless ctx no longer carries
+// one of l's fields because of WithoutFields, in which case the logger is re-derived from
+// the fields of ctx on the logger at the root of the For chain.
+func (l *slogLogger) withoutRemoved(ctx context.Context) *slogLogger {
+	fs := fieldsFrom(ctx)
+	if fs == nil || fs == l.fields || len(fs.removed) == 0 {
+		return l
+	}
+
+	for _, k := range fs.removed {
+		if slices.ContainsFunc(l.fields.attrs, func(a slog.Attr) bool { return a.Key == k }) {
+			derived, _ := l.root().For(ctx).(*slogLogger)
+
+			return derived
+		}
+	}
+
+	return l
+}
+
+// root returns the logger l was derived from with For, or l itself.
+func (l *slogLogger) root() *slogLogger {
+	for l.fields != nil {
+		l = l.base
+	}
+
+	return l
+}
+
+// sameAttr reports whether a and b have the same key and value. Unlike slog.Attr.Equal it
+// does not panic on values of uncomparable types such as slices, which it compares deeply.
+func sameAttr(a, b slog.Attr) bool {
+	if a.Key != b.Key || a.Value.Kind() != b.Value.Kind() {
+		return false
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindAny, slog.KindLogValuer:
+		return reflect.DeepEqual(a.Value.Any(), b.Value.Any())
+	case slog.KindGroup:
+		return slices.EqualFunc(a.Value.Group(), b.Value.Group(), sameAttr)
+	default:
+		return a.Value.Equal(b.Value)
+	}
+}
+
+// enabled reports whether level passes the per-request override from ctx, or the
+// configured level when there is none, and the handler chain.
+func (l *slogLogger) enabled(ctx context.Context, level slog.Level) bool {
+	minLevel := l.level
+	if o, ok := LevelOverride(ctx); ok {
+		minLevel = o
+	}
+
+	return level >= minLevel && l.handler.Enabled(ctx, level)
+}
+
+// log is the single hot path shared by the *Ctx methods. It must be called directly by
+// them so that the caller frame skipped for source information is the application's.
+func (l *slogLogger) log(ctx context.Context, level slog.Level, msg string, err error, kv []any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
-	ctx, kv = l.withCtx(ctx, kv)
+	if l.fields != nil {
+		l = l.withoutRemoved(ctx)
+	}
+
+	// Records below the level are built only when a record buffer may hold them.
+	var held *recordBuffer
+
+	if !l.enabled(ctx, level) {
+		if held = bufferFrom(ctx); held == nil || !l.handler.Enabled(ctx, level) {
+			return
+		}
+	}
+
+	var pc uintptr
+
+	if l.addSource {
+		var pcs [1]uintptr
+		runtime.Callers(callerSkip, pcs[:])
+		pc = pcs[0]
+	}
+
+	r := slog.NewRecord(time.Now(), level, msg, pc)
+
+	bp, _ := attrPool.Get().(*[]slog.Attr)
+	attrs := (*bp)[:0]
+
+	if l.autoCtx {
+		attrs = l.appendContextFields(ctx, attrs)
+	}
+
+	callStart := len(attrs)
+	attrs = utils.AppendAttrs(attrs, kv)
+
+	if l.templates {
+		if t := templateFor(msg); t != nil {
+			r.Message, attrs = l.applyTemplate(t, msg, attrs, attrs[callStart:])
+		}
+	}
+
+	l.protectReserved(attrs)
+
+	// Context extractors, including OTel trace/span correlation by default
+	for _, extract := range l.extract {
+		attrs = extract(ctx, attrs)
+	}
 
 	if err != nil {
-		kv = append(kv, slog.String("error", err.Error()))
+		attrs = append(attrs, slog.String(ih.KeyError, err.Error()))
 	}
 
-	l.core.ErrorContext(ctx, msg, kv...)
+	r.AddAttrs(attrs...)
+
+	switch {
+	case held != nil:
+		held.hold(l.handler, r)
+	case level >= slog.LevelError:
+		if b := bufferFrom(ctx); b != nil {
+			b.flush(ctx)
+		}
+
+		_ = l.handler.Handle(ctx, r)
+	default:
+		_ = l.handler.Handle(ctx, r)
+	}
+
+	if cap(attrs) <= maxPooledAttrs {
+		clear(attrs)
+		*bp = attrs[:0]
+		attrPool.Put(bp)
+	}
 }
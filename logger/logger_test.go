@@ -8,11 +8,13 @@ import (
 	"log/slog"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/next-trace/scg-logger/logger"
+	"github.com/next-trace/scg-logger/logger/handlers"
 	"go.opentelemetry.io/otel"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	nooptrace "go.opentelemetry.io/otel/trace/noop"
@@ -230,6 +232,79 @@ func TestFromContextFallbackReturnsNoop(t *testing.T) {
 	}
 }
 
+// TestConsoleColorsIgnoreBudgetWriter ensures the byte budget's writer wrapper does not turn
+// colors off for a terminal. The colored record overdraws a byte budget the plain one fits in.
+func TestConsoleColorsIgnoreBudgetWriter(t *testing.T) {
+	tty, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil || !handlers.ColorEnabled(tty) {
+		t.Skip("no character device to stand in for a terminal")
+	}
+	defer tty.Close()
+
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm")
+
+	budget := handlers.NewBudget(handlers.BudgetOptions{BytesPerSecond: 1, BurstBytes: 30})
+	l := logger.New(logger.WithWriter(tty), logger.WithFormat(logger.FormatConsole), logger.WithBudget(budget))
+
+	l.InfoCtx(t.Context(), "msg") // 23 bytes plain, 48 colored
+	l.InfoCtx(t.Context(), "msg")
+
+	if budget.Dropped() != 1 {
+		t.Fatalf("expected colored output to spend the byte budget, dropped %d", budget.Dropped())
+	}
+}
+
+// TestFlushWritesPendingSummaries ensures logger.Flush reaches handlers holding reports back.
+func TestFlushWritesPendingSummaries(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithService("svc"), logger.WithDedup(time.Minute),
+		logger.WithSampling(logger.SamplingOptions{Interval: time.Hour, First: 1}))
+	ctx := t.Context()
+
+	for i := range 3 {
+		l.InfoCtx(ctx, "hot path", "n", i)
+	}
+
+	for range 3 {
+		l.WarnCtx(ctx, "retrying")
+	}
+
+	if err := logger.Flush(ctx, l); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `"sampled_msg":"hot path","dropped":2`) {
+		t.Fatalf("expected a sampling summary on flush: %s", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), `"repeated_msg":"retrying","repeated":2`) {
+		t.Fatalf("expected a repeat report on flush: %s", buf.String())
+	}
+
+	buf.Reset()
+
+	budget := handlers.NewBudget(handlers.BudgetOptions{RecordsPerSecond: 1, ReportInterval: time.Hour})
+	l = logger.New(logger.WithWriter(&buf), logger.WithBudget(budget))
+
+	for range 3 {
+		l.InfoCtx(ctx, "spike")
+	}
+
+	if err := logger.Flush(ctx, l); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `"shed":2,"shed_total":2`) {
+		t.Fatalf("expected a shed report on flush: %s", buf.String())
+	}
+
+	if err := logger.Flush(ctx, logger.FromContext(ctx)); err != nil {
+		t.Fatalf("expected flushing a no-op logger to succeed: %v", err)
+	}
+}
+
 func TestMustInitDefaultOverrides(t *testing.T) {
 	// Initialize default with a recognizable service name while stdout is captured
 	// so the handler binds to the captured writer.
@@ -366,17 +441,298 @@ func TestIntoContextWithNilCtx(t *testing.T) {
 	}
 }
 
-// Benchmark to ensure no heavy reflection penalties (non-critical for tests but keeps coverage meaningful).
-func BenchmarkInfo(b *testing.B) {
-	_ = captureStdout(&testing.T{}, func() {
-		l := logger.New(logger.WithLevel("info"))
+func TestWithLimitsTruncatesLargeValues(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithLimits(logger.Limits{MaxStringLength: 4}))
+	l.InfoCtx(t.Context(), "large", "body", strings.Repeat("x", 1024))
+
+	m := parseFirstJSONLine(t, buf.String())
+
+	if m["body"] != "xxxx" || m["body_truncated"] != float64(1024) {
+		t.Fatalf("expected body truncated with original size: %v", m)
+	}
+}
+
+func TestReservedKeysAreRenamed(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithService("svc"))
+	l.InfoCtx(t.Context(), "reserved", "level", "custom", "service", "other", "msg", "m")
+
+	m := parseFirstJSONLine(t, buf.String())
+
+	if m["level"] != "INFO" || m["service"] != "svc" || m["msg"] != "reserved" {
+		t.Fatalf("expected built-in keys preserved: %v", m)
+	}
+
+	if m["fields.level"] != "custom" || m["fields.service"] != "other" || m["fields.msg"] != "m" {
+		t.Fatalf("expected caller keys renamed with prefix: %v", m)
+	}
+}
+
+func TestTraceIDFromContextFieldsIsKept(t *testing.T) {
+	var buf bytes.Buffer
+
+	// The documented middleware usage: a trace_id attached with WithFields reaches the output as is.
+	l := logger.New(logger.WithWriter(&buf), logger.WithService("svc"))
+	ctx := logger.WithFields(t.Context(), map[string]any{"trace_id": "abc-123"})
+	l.For(ctx).InfoCtx(ctx, "Doing work")
+
+	m := parseFirstJSONLine(t, buf.String())
+	if m["trace_id"] != "abc-123" || m["fields.trace_id"] != nil {
+		t.Fatalf("expected trace_id from context fields unchanged: %s", buf.String())
+	}
+}
+
+func TestTraceIDFromSpanWinsOverContextField(t *testing.T) {
+	var buf bytes.Buffer
+
+	tr := sdktrace.NewTracerProvider().Tracer("test")
+
+	ctx, span := tr.Start(t.Context(), "op")
+	defer span.End()
+
+	l := logger.New(logger.WithWriter(&buf))
+	ctx = logger.WithFields(ctx, map[string]any{"trace_id": "abc-123"})
+	l.For(ctx).InfoCtx(ctx, "both")
+
+	out := buf.String()
+	if strings.Count(out, `"trace_id"`) != 1 || !strings.Contains(out, span.SpanContext().TraceID().String()) {
+		t.Fatalf("expected a single trace_id taken from the span: %s", out)
+	}
+}
+
+func TestServiceKeyIsFreeWithoutService(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf))
+	l.InfoCtx(t.Context(), "no service", "service", "caller")
+
+	if m := parseFirstJSONLine(t, buf.String()); m["service"] != "caller" {
+		t.Fatalf("expected caller service key unchanged when no service is set: %s", buf.String())
+	}
+}
+
+func TestContextAndCallSiteDuplicatesFollowPolicy(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithDuplicatePolicy(logger.DuplicateFirstWins))
+	ctx := logger.WithFields(t.Context(), map[string]any{"request_id": "from-ctx"})
+	l.For(ctx).InfoCtx(ctx, "dup", "request_id", "from-call")
+
+	out := buf.String()
+	if strings.Count(out, `"request_id"`) != 1 || !strings.Contains(out, `"request_id":"from-ctx"`) {
+		t.Fatalf("expected context field to win under first-wins: %s", out)
+	}
+}
+
+func TestWithSchemaRemapsBuiltInKeys(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithService("svc"), logger.WithSchema(handlers.SchemaECS()))
+	l.ErrorCtx(t.Context(), "schema", errors.New("bad"))
+
+	m := parseFirstJSONLine(t, buf.String())
+
+	if m["service.name"] != "svc" || m["log.level"] != "error" || m["error.message"] != "bad" {
+		t.Fatalf("expected ECS keys in output: %v", m)
+	}
+}
+
+func TestWithFormatLogfmt(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithFormat(logger.FormatLogfmt), logger.WithService("svc"))
+	l.InfoCtx(t.Context(), "logfmt line", "k", "v w")
+
+	out := buf.String()
+	if !strings.Contains(out, `msg="logfmt line" service=svc k="v w"`) {
+		t.Fatalf("expected logfmt output: %s", out)
+	}
+}
+
+func TestCallerPointsToCallSite(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithCaller(true))
+	l.InfoCtx(t.Context(), "caller")
+
+	if !strings.Contains(buf.String(), "logger_test.go") {
+		t.Fatalf("expected source to reference the call site: %s", buf.String())
+	}
+}
+
+func TestForReusesDerivedLogger(t *testing.T) {
+	l := logger.New(logger.WithWriter(io.Discard))
+	ctx := logger.WithFields(t.Context(), map[string]any{"request_id": "req-1"})
+
+	first := l.For(ctx)
+	if first == l {
+		t.Fatal("expected an enriched logger when fields are present")
+	}
+
+	if l.For(ctx) != first {
+		t.Fatal("expected For to return the cached derived logger")
+	}
+
+	if first.For(ctx) != first {
+		t.Fatal("expected For on a derived logger with the same fields to return itself")
+	}
+
+	child := logger.WithFields(ctx, map[string]any{"step": 2})
+	if first.For(child) != l.For(child) {
+		t.Fatal("expected For on a derived logger to reuse the logger derived from the root")
+	}
+}
+
+func TestNestedWithFieldsMerges(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf))
+	parent := logger.WithFields(t.Context(), map[string]any{"request_id": "req-1", "user": "ann"})
+	child := logger.WithFields(parent, map[string]any{"user": "bob", "step": 2})
+
+	l.For(parent).InfoCtx(parent, "parent")
+	l.For(child).InfoCtx(child, "child")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	p, c := parseJSONLine(t, lines[0]), parseJSONLine(t, lines[1])
+
+	if p["user"] != "ann" || p["step"] != nil {
+		t.Fatalf("expected parent fields untouched by child: %v", p)
+	}
+
+	if c["request_id"] != "req-1" || c["user"] != "bob" || c["step"] != float64(2) {
+		t.Fatalf("expected child to merge and override parent fields: %v", c)
+	}
 
-		ctx := b.Context()
+	for _, policy := range []logger.DuplicatePolicy{logger.DuplicatePrefix, logger.DuplicateAllow} {
+		buf.Reset()
 
-		for range b.N {
-			l.InfoCtx(ctx, "bench", slog.String("i", "1"))
+		l := logger.New(logger.WithWriter(&buf), logger.WithDuplicatePolicy(policy))
+		l.For(parent).For(child).InfoCtx(child, "chained")
+
+		out := buf.String()
+		if strings.Count(out, `"request_id"`) != 1 || strings.Count(out, `"user"`) != 1 ||
+			strings.Contains(out, "fields.") || !strings.Contains(out, `"user":"bob"`) {
+			t.Fatalf("policy %v: expected parent fields applied once by a chained For: %s", policy, out)
 		}
+	}
+}
+
+func TestWithDedupCollapsesRepeatedErrors(t *testing.T) {
+	var buf bytes.Buffer
 
-		time.Sleep(1 * time.Millisecond)
+	l := logger.New(logger.WithWriter(&buf), logger.WithDedup(time.Minute))
+
+	for range 3 {
+		l.ErrorCtx(t.Context(), "upstream unavailable", errors.New("dial tcp: refused"))
+	}
+
+	l.InfoCtx(t.Context(), "recovered")
+
+	out := buf.String()
+	if strings.Count(out, `"msg":"upstream unavailable"`) != 1 || !strings.Contains(out, `"repeated":2`) {
+		t.Fatalf("expected one error line and a repeat summary: %s", out)
+	}
+}
+
+func TestWithBudgetShedsLowLevelsFirst(t *testing.T) {
+	var buf bytes.Buffer
+
+	budget := handlers.NewBudget(handlers.BudgetOptions{RecordsPerSecond: 4, ErrorReserve: 0.5})
+	l := logger.New(logger.WithWriter(&buf), logger.WithBudget(budget))
+
+	for range 10 {
+		l.InfoCtx(t.Context(), "busy")
+	}
+
+	l.ErrorCtx(t.Context(), "still reported", errors.New("boom"))
+
+	out := buf.String()
+	if strings.Count(out, `"msg":"busy"`) != 2 || !strings.Contains(out, "still reported") || budget.Dropped() != 8 {
+		t.Fatalf("expected info to be shed and the error kept (dropped=%d): %s", budget.Dropped(), out)
+	}
+}
+
+func TestAttrsAndGroupsInKV(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf))
+	l.InfoCtx(t.Context(), "mixed", slog.String("i", "1"), "n", 2, slog.Group("g", "k", "v"), 7, "bad")
+
+	m := parseJSONLine(t, strings.TrimSpace(buf.String()))
+	if m["i"] != "1" || m["n"] != float64(2) || m["kv_error"] != nil {
+		t.Fatalf("expected attrs to take one slot: %s", buf.String())
+	}
+
+	if g, _ := m["g"].(map[string]any); g["k"] != "v" {
+		t.Fatalf("expected group: %s", buf.String())
+	}
+
+	if bad, _ := m["!BADKEY"].(map[string]any); bad["key"] != "7" || bad["value"] != "bad" {
+		t.Fatalf("expected non-string key to be reported: %s", buf.String())
+	}
+}
+
+func TestWithEncodersComposesWithSchema(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithSchema(handlers.SchemaECS()),
+		logger.WithEncoders(handlers.DefaultEncoders()))
+	l.InfoCtx(t.Context(), "encoded", "took", 2*time.Second, "id", int64(1)<<62)
+
+	m := parseJSONLine(t, strings.TrimSpace(buf.String()))
+	if m["message"] != "encoded" || m["took"] != "2s" || m["id"] != "4611686018427387904" {
+		t.Fatalf("expected encoded values under the schema: %s", buf.String())
+	}
+}
+
+func TestWithValidatorReportsConflictingTypes(t *testing.T) {
+	var got []handlers.Violation
+
+	v := handlers.NewValidator(handlers.ValidationOptions{
+		Learn:       true,
+		Required:    []string{"service"},
+		OnViolation: func(vi handlers.Violation) { got = append(got, vi) },
 	})
+	l := logger.New(logger.WithWriter(io.Discard), logger.WithService("billing"), logger.WithValidator(v))
+
+	ctx := logger.WithFields(t.Context(), map[string]any{"user_id": "u-1"})
+	l.For(ctx).InfoCtx(ctx, "from context")
+	l.InfoCtx(t.Context(), "from call site", "user_id", 7)
+
+	if len(got) != 1 || got[0].Key != "user_id" || got[0].Got != handlers.TypeNumber || v.Violations() != 1 {
+		t.Fatalf("expected one type conflict on user_id, got %+v", got)
+	}
+}
+
+func TestSchemaOutputKeysAreReserved(t *testing.T) {
+	presets := map[string]handlers.Schema{
+		"ecs":     handlers.SchemaECS(),
+		"gcp":     handlers.SchemaGCP("proj"),
+		"datadog": handlers.SchemaDatadog(),
+		"otel":    handlers.SchemaOTel(),
+	}
+
+	for name, schema := range presets {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range schema.OutputKeys() {
+				var buf bytes.Buffer
+
+				l := logger.New(logger.WithWriter(&buf), logger.WithService("svc"), logger.WithCaller(true),
+					logger.WithSchema(schema))
+				l.ErrorCtx(t.Context(), "hi", errors.New("boom"), key, "user-value")
+
+				if n := strings.Count(buf.String(), strconv.Quote(key)+":"); n > 1 {
+					t.Fatalf("%s written %d times: %s", key, n, buf.String())
+				}
+
+				if m := parseFirstJSONLine(t, buf.String()); m["fields."+key] != "user-value" {
+					t.Fatalf("expected caller %s to be renamed: %s", key, buf.String())
+				}
+			}
+		})
+	}
 }
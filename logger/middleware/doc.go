@@ -0,0 +1,3 @@
+// Package middleware provides net/http middleware that integrates request handling with
+// package logger, such as authorizing a per-request level override.
+package middleware
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/next-trace/scg-logger/logger"
+)
+
+// Where LevelOverride looks for a signed level token.
+const (
+	HeaderLogLevel = "X-Log-Level"
+	QueryLogLevel  = "log_level"
+)
+
+// tokenParts is the number of dot-separated parts of a token: level, expiry, signature.
+const tokenParts = 3
+
+// SignLevel returns a token authorizing requests to be logged at level until expires.
+// The token has the form "<level>.<unix expiry>.<hex HMAC-SHA256>" and is meant to be
+// generated by an operator tool holding the same secret as the service.
+func SignLevel(secret []byte, level string, expires time.Time) string {
+	payload := level + "." + strconv.FormatInt(expires.Unix(), 10)
+
+	return payload + "." + hex.EncodeToString(sign(secret, payload))
+}
+
+// LevelOverride returns middleware that applies logger.WithLevelOverride to the request
+// context when the X-Log-Level header (or the log_level query parameter) carries a valid,
+// unexpired token from SignLevel. Missing or invalid tokens are ignored, so the request is
+// logged at the service level. An empty secret disables the middleware.
+func LevelOverride(secret []byte) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(secret) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := r.Header.Get(HeaderLogLevel)
+			if token == "" {
+				token = r.URL.Query().Get(QueryLogLevel)
+			}
+
+			if level, ok := verify(secret, token, time.Now()); ok {
+				r = r.WithContext(logger.WithLevelOverride(r.Context(), level))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// verify checks token and returns the level it authorizes.
+func verify(secret []byte, token string, now time.Time) (string, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != tokenParts {
+		return "", false
+	}
+
+	level, expiry, sig := parts[0], parts[1], parts[2]
+
+	exp, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || now.Unix() > exp {
+		return "", false
+	}
+
+	got, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, sign(secret, level+"."+expiry)) {
+		return "", false
+	}
+
+	return level, true
+}
+
+func sign(secret []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+
+	return mac.Sum(nil)
+}
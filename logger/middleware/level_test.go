@@ -0,0 +1,74 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-logger/logger"
+	"github.com/next-trace/scg-logger/logger/middleware"
+)
+
+var secret = []byte("test-secret")
+
+func serve(t *testing.T, mutate func(r *http.Request)) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithLevel("info"))
+	h := middleware.LevelOverride(secret)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		l.DebugCtx(r.Context(), "debug detail")
+	}))
+
+	r := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/orders", nil)
+	mutate(r)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	return buf.String()
+}
+
+func TestLevelOverrideFromSignedHeader(t *testing.T) {
+	out := serve(t, func(r *http.Request) {
+		r.Header.Set(middleware.HeaderLogLevel, middleware.SignLevel(secret, "debug", time.Now().Add(time.Minute)))
+	})
+
+	if !strings.Contains(out, "debug detail") {
+		t.Fatalf("expected debug record for signed request: %q", out)
+	}
+}
+
+func TestLevelOverrideFromQuery(t *testing.T) {
+	out := serve(t, func(r *http.Request) {
+		token := middleware.SignLevel(secret, "debug", time.Now().Add(time.Minute))
+		r.URL.RawQuery = url.Values{middleware.QueryLogLevel: {token}}.Encode()
+	})
+
+	if !strings.Contains(out, "debug detail") {
+		t.Fatalf("expected debug record for signed query parameter: %q", out)
+	}
+}
+
+func TestLevelOverrideRejectsInvalidTokens(t *testing.T) {
+	tokens := map[string]string{
+		"unsigned":      "debug",
+		"wrong secret":  middleware.SignLevel([]byte("other"), "debug", time.Now().Add(time.Minute)),
+		"expired":       middleware.SignLevel(secret, "debug", time.Now().Add(-time.Minute)),
+		"tampered":      strings.Replace(middleware.SignLevel(secret, "error", time.Now().Add(time.Minute)), "error", "debug", 1),
+		"invalid level": middleware.SignLevel(secret, "verbose", time.Now().Add(time.Minute)),
+	}
+
+	for name, token := range tokens {
+		t.Run(name, func(t *testing.T) {
+			out := serve(t, func(r *http.Request) { r.Header.Set(middleware.HeaderLogLevel, token) })
+
+			if out != "" {
+				t.Fatalf("expected no debug output for %s token: %q", name, out)
+			}
+		})
+	}
+}
@@ -2,24 +2,34 @@ package logger
 
 import (
 	"context"
+	"encoding/hex"
+	"log/slog"
 
+	ih "github.com/next-trace/scg-logger/logger/handlers"
 	"go.opentelemetry.io/otel/trace"
 )
 
-// addTraceKV appends OpenTelemetry correlation IDs to kv if a span exists in ctx.
-func addTraceKV(ctx context.Context, kv []any) []any {
-	if ctx == nil {
-		return kv
-	}
-
-	span := trace.SpanFromContext(ctx)
+// traceHexLen is the hex length of a trace ID followed by a span ID.
+const traceHexLen = 2*len(trace.TraceID{}) + 2*len(trace.SpanID{})
 
-	sc := span.SpanContext()
+// TraceExtractor appends OpenTelemetry correlation IDs (trace_id, span_id) to attrs if a
+// valid span exists in ctx. It is registered by default as ExtractorTrace.
+func TraceExtractor(ctx context.Context, attrs []slog.Attr) []slog.Attr {
+	sc := trace.SpanContextFromContext(ctx)
 
 	if !sc.HasTraceID() || !sc.HasSpanID() {
-		return kv
+		return attrs
 	}
 
-	// Append trace_id and span_id keeping original kv intact.
-	return append(kv, "trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+	// Encode both IDs into one string so the pair costs a single allocation.
+	tid, sid := sc.TraceID(), sc.SpanID()
+
+	var buf [traceHexLen]byte
+
+	n := hex.Encode(buf[:], tid[:])
+	hex.Encode(buf[n:], sid[:])
+
+	ids := string(buf[:])
+
+	return append(attrs, slog.String(ih.KeyTraceID, ids[:n]), slog.String(ih.KeySpanID, ids[n:]))
 }
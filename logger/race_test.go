@@ -0,0 +1,7 @@
+//go:build race
+
+package logger_test
+
+// raceEnabled reports that the race detector is on; sync.Pool drops items at random
+// under it, so allocation budgets are not meaningful.
+const raceEnabled = true
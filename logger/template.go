@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	ih "github.com/next-trace/scg-logger/logger/handlers"
+)
+
+// Keys added to records whose message is a template, see WithMessageTemplates.
+const (
+	KeyMsgTemplate        = ih.KeyMsgTemplate
+	KeyMsgTemplateMissing = "msg_template_missing" // placeholders without a value
+	KeyMsgTemplateUnused  = "msg_template_unused"  // call-site fields no placeholder refers to
+)
+
+// maxCachedTemplates bounds the parse cache when messages are built dynamically.
+const maxCachedTemplates = 1024
+
+// msgTemplate is a parsed message template: literal text and placeholder names, in order.
+type msgTemplate struct {
+	parts []templatePart
+	names []string
+}
+
+type templatePart struct {
+	text        string
+	placeholder bool
+}
+
+var (
+	templateCache sync.Map // message -> *msgTemplate, nil when it has no placeholders
+	templateCount atomic.Int64
+)
+
+// templateFor returns the parsed template for msg, or nil when msg has no placeholders.
+func templateFor(msg string) *msgTemplate {
+	if !strings.Contains(msg, "{") {
+		return nil
+	}
+
+	if t, ok := templateCache.Load(msg); ok {
+		return t.(*msgTemplate) //nolint:forcetypeassert // only *msgTemplate is stored.
+	}
+
+	t := parseTemplate(msg)
+
+	if templateCount.Load() < maxCachedTemplates {
+		if _, loaded := templateCache.LoadOrStore(msg, t); !loaded {
+			templateCount.Add(1)
+		}
+	}
+
+	return t
+}
+
+// parseTemplate splits msg into literals and {name} placeholders. Names are made of
+// letters, digits, '_', '.' and '-'; any other brace is literal text, and "{{" and "}}"
+// are escaped braces.
+func parseTemplate(msg string) *msgTemplate {
+	t := &msgTemplate{}
+
+	var lit strings.Builder
+
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+
+		if (c == '{' || c == '}') && i+1 < len(msg) && msg[i+1] == c {
+			lit.WriteByte(c)
+			i++
+
+			continue
+		}
+
+		if c == '{' {
+			if end := strings.IndexByte(msg[i+1:], '}'); end > 0 && isPlaceholderName(msg[i+1:i+1+end]) {
+				if lit.Len() > 0 {
+					t.parts = append(t.parts, templatePart{text: lit.String()})
+					lit.Reset()
+				}
+
+				name := msg[i+1 : i+1+end]
+				t.parts = append(t.parts, templatePart{text: name, placeholder: true})
+
+				if !slices.Contains(t.names, name) {
+					t.names = append(t.names, name)
+				}
+
+				i += end + 1
+
+				continue
+			}
+		}
+
+		lit.WriteByte(c)
+	}
+
+	if len(t.names) == 0 {
+		return nil
+	}
+
+	if lit.Len() > 0 {
+		t.parts = append(t.parts, templatePart{text: lit.String()})
+	}
+
+	return t
+}
+
+func isPlaceholderName(s string) bool {
+	for _, c := range []byte(s) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_', c == '.', c == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// render fills the placeholders from attrs, the record's attributes so far, falling back
+// to the logger's own context fields. Placeholders without a value are kept as written.
+func (t *msgTemplate) render(attrs []slog.Attr, fields *fieldSet) (string, []string) {
+	var (
+		b       strings.Builder
+		missing []string
+	)
+
+	for _, p := range t.parts {
+		if !p.placeholder {
+			b.WriteString(p.text)
+
+			continue
+		}
+
+		v, ok := lookupAttr(attrs, p.text)
+		if !ok && fields != nil {
+			v, ok = lookupAttr(fields.attrs, p.text)
+		}
+
+		if !ok {
+			b.WriteString("{" + p.text + "}")
+
+			if !slices.Contains(missing, p.text) {
+				missing = append(missing, p.text)
+			}
+
+			continue
+		}
+
+		b.WriteString(v.Resolve().String())
+	}
+
+	return b.String(), missing
+}
+
+// lookupAttr returns the value of the last attribute named key, as it would win on output.
+func lookupAttr(attrs []slog.Attr, key string) (slog.Value, bool) {
+	for i := len(attrs) - 1; i >= 0; i-- {
+		if attrs[i].Key == key {
+			return attrs[i].Value, true
+		}
+	}
+
+	return slog.Value{}, false
+}
+
+// applyTemplate renders a record's message from its template and appends the template and
+// any problems found to attrs. call holds the call-site attributes, a suffix of attrs.
+func (l *slogLogger) applyTemplate(t *msgTemplate, tmpl string, attrs, call []slog.Attr) (string, []slog.Attr) {
+	msg, missing := t.render(attrs, l.fields)
+
+	var unused []string
+
+	for _, a := range call {
+		if a.Key != "" && !slices.Contains(t.names, a.Key) {
+			unused = append(unused, a.Key)
+		}
+	}
+
+	attrs = append(attrs, slog.String(KeyMsgTemplate, tmpl))
+
+	if len(missing) > 0 {
+		attrs = append(attrs, slog.Any(KeyMsgTemplateMissing, missing))
+	}
+
+	if len(unused) > 0 {
+		attrs = append(attrs, slog.Any(KeyMsgTemplateUnused, unused))
+	}
+
+	return msg, attrs
+}
@@ -0,0 +1,100 @@
+package logger_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/next-trace/scg-logger/logger"
+)
+
+func TestMessageTemplateRendersAndKeepsTemplate(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithMessageTemplates(true))
+	l.InfoCtx(t.Context(), "user {user_id} bought {count} items", "user_id", 7, "count", 3)
+
+	m := parseJSONLine(t, strings.TrimSpace(buf.String()))
+	if m["msg"] != "user 7 bought 3 items" || m["msg_template"] != "user {user_id} bought {count} items" {
+		t.Fatalf("unexpected message: %s", buf.String())
+	}
+
+	if m["user_id"] != float64(7) || m["count"] != float64(3) {
+		t.Fatalf("expected values to stay structured fields: %s", buf.String())
+	}
+
+	if _, ok := m["msg_template_missing"]; ok {
+		t.Fatalf("expected no problems reported: %s", buf.String())
+	}
+}
+
+func TestMessageTemplateReportsMissingAndUnused(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithMessageTemplates(true))
+	l.WarnCtx(t.Context(), "order {order_id} failed for {user}", "order_id", "o-1", "retry", true)
+
+	m := parseJSONLine(t, strings.TrimSpace(buf.String()))
+	if m["msg"] != "order o-1 failed for {user}" {
+		t.Fatalf("expected missing placeholder to be kept: %s", buf.String())
+	}
+
+	missing, _ := m["msg_template_missing"].([]any)
+	unused, _ := m["msg_template_unused"].([]any)
+
+	if len(missing) != 1 || missing[0] != "user" || len(unused) != 1 || unused[0] != "retry" {
+		t.Fatalf("expected missing and unused keys to be reported: %s", buf.String())
+	}
+}
+
+func TestMessageTemplateUsesContextFieldsAndEscapes(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithMessageTemplates(true))
+	ctx := logger.WithFields(t.Context(), map[string]any{"tenant": "acme"})
+	l.For(ctx).InfoCtx(ctx, "{{literal}} for {tenant} with {\"json\": 1}")
+
+	m := parseJSONLine(t, strings.TrimSpace(buf.String()))
+	if m["msg"] != `{literal} for acme with {"json": 1}` {
+		t.Fatalf("unexpected rendering: %s", buf.String())
+	}
+}
+
+func TestMessageTemplatesAreOptIn(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf))
+	l.InfoCtx(t.Context(), "user {user_id}", "user_id", 7)
+
+	m := parseJSONLine(t, strings.TrimSpace(buf.String()))
+	if m["msg"] != "user {user_id}" || m["msg_template"] != nil {
+		t.Fatalf("expected messages untouched by default: %s", buf.String())
+	}
+}
+
+// TestMessageTemplatesAreSampledByTemplate ensures rendered messages from one template share
+// a sampling group.
+func TestMessageTemplatesAreSampledByTemplate(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := logger.New(logger.WithWriter(&buf), logger.WithMessageTemplates(true),
+		logger.WithSampling(logger.SamplingOptions{Interval: time.Hour, First: 2}))
+
+	for i := range 5 {
+		l.InfoCtx(t.Context(), "user {user_id} logged in", "user_id", i)
+	}
+
+	if err := logger.Flush(t.Context(), l); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	out := buf.String()
+	if got := strings.Count(out, `"msg_template":"user {user_id} logged in"`); got != 2 {
+		t.Fatalf("expected the first two records of the template, got %d: %s", got, out)
+	}
+
+	if !strings.Contains(out, `"sampled_msg":"user {user_id} logged in","dropped":3`) {
+		t.Fatalf("expected the summary to name the template: %s", out)
+	}
+}
@@ -1,8 +1,19 @@
 package utils
 
+import (
+	"fmt"
+	"log/slog"
+)
+
+// BadKey replaces a key that is not a string. The attribute's value is a group holding the
+// original key, stringified, and the value that came with it.
+const BadKey = "!BADKEY"
+
 // SanitizeKV normalizes key-value pairs for structured logging.
 // - If the length is odd, it drops the last dangling element and appends kv_error="odd_length".
-// - Keys must be strings; non-string keys degrade to an empty string to avoid panics.
+// - Keys must be strings; a non-string key becomes BadKey={key, value} so nothing is lost.
+// - A slog.Attr takes one slot and is kept as is; a group slog.Value takes one slot and
+// its attributes are inlined.
 func SanitizeKV(kv []any) []any {
 	if len(kv) == 0 {
 		return kv
@@ -11,26 +22,88 @@ func SanitizeKV(kv []any) []any {
 	const two = 2
 
 	out := make([]any, 0, len(kv)+two)
-	pairs := len(kv) / two
 
-	for i := 0; i < pairs*two; i += two {
-		key := kv[i]
-		val := kv[i+1]
+	for i := 0; i < len(kv); {
+		if a, ok := kv[i].(slog.Attr); ok {
+			out = append(out, a)
+			i++
 
-		var ks string
-		switch k := key.(type) {
-		case string:
-			ks = k
-		default:
-			ks = ""
+			continue
 		}
 
-		out = append(out, ks, val)
-	}
+		if g, ok := groupValue(kv[i]); ok {
+			for _, a := range g.Group() {
+				out = append(out, a)
+			}
+
+			i++
+
+			continue
+		}
+
+		if i+1 == len(kv) {
+			return append(out, "kv_error", "odd_length")
+		}
 
-	if len(kv)%two != 0 {
-		out = append(out, "kv_error", "odd_length")
+		if ks, ok := kv[i].(string); ok {
+			out = append(out, ks, kv[i+1])
+		} else {
+			out = append(out, badKey(kv[i], kv[i+1]))
+		}
+
+		i += two
 	}
 
 	return out
 }
+
+// AppendAttrs appends the key-value pairs in kv to dst as slog attributes, applying the
+// same normalization as SanitizeKV without building an intermediate []any.
+// A slog.Attr element (e.g. from package fields) is appended as is and takes one slot, and
+// the attributes of a group slog.Value are inlined.
+func AppendAttrs(dst []slog.Attr, kv []any) []slog.Attr {
+	for i := 0; i < len(kv); {
+		if a, ok := kv[i].(slog.Attr); ok {
+			dst = append(dst, a)
+			i++
+
+			continue
+		}
+
+		if g, ok := groupValue(kv[i]); ok {
+			dst = append(dst, g.Group()...)
+			i++
+
+			continue
+		}
+
+		if i+1 == len(kv) {
+			return append(dst, slog.String("kv_error", "odd_length"))
+		}
+
+		if ks, ok := kv[i].(string); ok {
+			dst = append(dst, slog.Any(ks, kv[i+1]))
+		} else {
+			dst = append(dst, badKey(kv[i], kv[i+1]))
+		}
+
+		i += 2
+	}
+
+	return dst
+}
+
+// groupValue reports whether v is a group slog.Value, whose attributes are inlined.
+func groupValue(v any) (slog.Value, bool) {
+	g, ok := v.(slog.Value)
+
+	return g, ok && g.Kind() == slog.KindGroup
+}
+
+// badKey reports a pair whose key is not a string.
+func badKey(key, value any) slog.Attr {
+	return slog.Attr{Key: BadKey, Value: slog.GroupValue(
+		slog.String("key", fmt.Sprint(key)),
+		slog.Any("value", value),
+	)}
+}
@@ -1,6 +1,7 @@
 package utils_test
 
 import (
+	"log/slog"
 	"reflect"
 	"testing"
 
@@ -38,15 +39,62 @@ func TestSanitizeKV_OddLengthAddsErrorAndDropsLast(t *testing.T) {
 	}
 }
 
-func TestSanitizeKV_NonStringKeyBecomesEmpty(t *testing.T) {
+func TestSanitizeKV_NonStringKeyBecomesBadKey(t *testing.T) {
 	in := []any{123, "v"}
 	out := utils.SanitizeKV(in)
 
-	if len(out) != 2 {
+	if len(out) != 1 {
 		t.Fatalf("unexpected length: %d", len(out))
 	}
 
-	if s, ok := out[0].(string); !ok || s != "" {
-		t.Fatalf("expected empty string key, got %#v", out[0])
+	want := slog.Group(utils.BadKey, slog.String("key", "123"), slog.String("value", "v"))
+	if a, ok := out[0].(slog.Attr); !ok || !a.Equal(want) {
+		t.Fatalf("expected %v, got %#v", want, out[0])
+	}
+}
+
+func TestSanitizeKV_AcceptsAttrsAndGroups(t *testing.T) {
+	in := []any{slog.String("i", "1"), "a", 2, slog.GroupValue(slog.Int("x", 1), slog.Int("y", 2))}
+	out := utils.SanitizeKV(in)
+
+	want := []any{slog.String("i", "1"), "a", 2, slog.Int("x", 1), slog.Int("y", 2)}
+	if !reflect.DeepEqual(out, want) {
+		t.Fatalf("expected attrs kept and group inlined, got %#v", out)
+	}
+}
+
+func TestAppendAttrs_MatchesSanitizeKV(t *testing.T) {
+	in := []any{"a", 1, 123, "v", "dangling"}
+	out := utils.AppendAttrs(nil, in)
+
+	want := []slog.Attr{
+		slog.Int("a", 1),
+		slog.Group(utils.BadKey, slog.String("key", "123"), slog.String("value", "v")),
+		slog.String("kv_error", "odd_length"),
+	}
+	if len(out) != len(want) {
+		t.Fatalf("unexpected attrs: %v", out)
+	}
+
+	for i := range want {
+		if !out[i].Equal(want[i]) {
+			t.Fatalf("attr %d: got %v, want %v", i, out[i], want[i])
+		}
+	}
+}
+
+func TestAppendAttrs_AcceptsAttrElements(t *testing.T) {
+	in := []any{slog.String("a", "1"), "b", 2, slog.Bool("c", true)}
+	out := utils.AppendAttrs(nil, in)
+
+	want := []slog.Attr{slog.String("a", "1"), slog.Int("b", 2), slog.Bool("c", true)}
+	if len(out) != len(want) {
+		t.Fatalf("unexpected attrs: %v", out)
+	}
+
+	for i := range want {
+		if !out[i].Equal(want[i]) {
+			t.Fatalf("attr %d: got %v, want %v", i, out[i], want[i])
+		}
 	}
 }